@@ -19,5 +19,5 @@ import (
 )
 
 func init() {
-	registerSubsystem(optimize.NewSubCmd())
+	registerSubSystemWithConfig(optimize.NewSubCmd(), &optimize.GlobalConfig)
 }
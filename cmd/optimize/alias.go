@@ -0,0 +1,76 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+// attributeAliases renames event attribute keys for presentation, mapping original names to aliases
+type attributeAliases map[string]string
+
+// parseAttributeAliases parses the --alias values of the form "original=alias"
+func parseAttributeAliases(values []string) (attributeAliases, error) {
+	aliases := make(attributeAliases, len(values))
+	for _, value := range values {
+		from, to, found := strings.Cut(value, "=")
+		if !found || from == "" || to == "" {
+			return nil, fmt.Errorf("invalid alias %q, expected the form attribute=alias", value)
+		}
+		aliases[from] = to
+	}
+	return aliases, nil
+}
+
+// apply renames the aliased attribute keys of each row in place. An alias colliding with an existing key of a row is
+// not applied to that row and a warning is logged once per alias
+func (aliases attributeAliases) apply(rows []EventsRow) {
+	if len(aliases) == 0 {
+		return
+	}
+	warned := make(map[string]bool)
+	for _, row := range rows {
+		for from, to := range aliases {
+			value, ok := row.EventAttributes[from]
+			if !ok {
+				continue
+			}
+			if _, exists := row.EventAttributes[to]; exists {
+				if !warned[from] {
+					log.Warnf("Alias %q for attribute %q collides with an existing attribute, leaving it unaliased", to, from)
+					warned[from] = true
+				}
+				continue
+			}
+			delete(row.EventAttributes, from)
+			row.EventAttributes[to] = value
+		}
+	}
+}
+
+// applyToAnnotations rewrites the attribute references of the command's table and detail field specifications
+// so that the human output columns still find the aliased attributes
+func (aliases attributeAliases) applyToAnnotations(cmd *cobra.Command) {
+	for name, spec := range cmd.Annotations {
+		for from, to := range aliases {
+			spec = strings.ReplaceAll(spec, fmt.Sprintf("[%q]", from), fmt.Sprintf("[%q]", to))
+		}
+		cmd.Annotations[name] = spec
+	}
+}
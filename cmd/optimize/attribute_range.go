@@ -0,0 +1,154 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// attributeRange bounds the time carried by an event attribute, such as the start of a measurement window, as
+// opposed to the --since/--until window which bounds the time at which events were emitted. Zero bounds are open
+type attributeRange struct {
+	attribute string
+	since     time.Time // inclusive
+	until     time.Time // exclusive
+}
+
+// attributeRanges are the --attr-since and --attr-until bounds, one range per attribute
+type attributeRanges []*attributeRange
+
+// parseAttributeRanges parses the --attr-since and --attr-until values of the form attribute=time, where time is
+// an RFC3339 time or a unix timestamp in seconds or milliseconds
+func parseAttributeRanges(sinceBounds []string, untilBounds []string) (attributeRanges, error) {
+	var ranges attributeRanges
+	lookup := func(attribute string) *attributeRange {
+		for _, r := range ranges {
+			if r.attribute == attribute {
+				return r
+			}
+		}
+		r := &attributeRange{attribute: attribute}
+		ranges = append(ranges, r)
+		return r
+	}
+	for _, bound := range sinceBounds {
+		attribute, bound, err := parseAttributeBound("attr-since", bound)
+		if err != nil {
+			return nil, err
+		}
+		lookup(attribute).since = bound
+	}
+	for _, bound := range untilBounds {
+		attribute, bound, err := parseAttributeBound("attr-until", bound)
+		if err != nil {
+			return nil, err
+		}
+		lookup(attribute).until = bound
+	}
+	for _, r := range ranges {
+		if !r.since.IsZero() && !r.until.IsZero() && !r.since.Before(r.until) {
+			return nil, fmt.Errorf("empty range for attribute %q, --attr-since must be before --attr-until", r.attribute)
+		}
+	}
+	return ranges, nil
+}
+
+func parseAttributeBound(flagName string, value string) (string, time.Time, error) {
+	attribute, bound, ok := strings.Cut(value, "=")
+	attribute, bound = strings.TrimSpace(attribute), strings.TrimSpace(bound)
+	if !ok || attribute == "" || bound == "" {
+		return "", time.Time{}, fmt.Errorf("invalid --%v %q, expected attribute=time", flagName, value)
+	}
+	converted, err := convertUnixTimestamp(flagName, bound)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339Nano, converted)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("invalid --%v time %q, expected an RFC3339 time or a unix timestamp: %w", flagName, bound, err)
+	}
+	return attribute, t, nil
+}
+
+// filter returns the rows whose attributes fall within all ranges. Rows missing a bounded attribute, or whose value
+// isn't a time, are dropped
+func (ranges attributeRanges) filter(rows []EventsRow) []EventsRow {
+	if len(ranges) == 0 {
+		return rows
+	}
+	results := make([]EventsRow, 0, len(rows))
+rowLoop:
+	for _, row := range rows {
+		for _, r := range ranges {
+			t, ok := attributeTime(row.EventAttributes[r.attribute])
+			if !ok || (!r.since.IsZero() && t.Before(r.since)) || (!r.until.IsZero() && !t.Before(r.until)) {
+				continue rowLoop
+			}
+		}
+		results = append(results, row)
+	}
+	return results
+}
+
+// attributeTime interprets an attribute value as a time, either RFC3339 or a unix timestamp in seconds or
+// milliseconds, told apart by magnitude
+func attributeTime(value any) (time.Time, bool) {
+	var number float64
+	switch v := value.(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t, true
+		}
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		number = parsed
+	case json.Number:
+		parsed, err := v.Float64()
+		if err != nil {
+			return time.Time{}, false
+		}
+		number = parsed
+	case float64:
+		number = v
+	case int64:
+		number = float64(v)
+	case int:
+		number = float64(v)
+	default:
+		return time.Time{}, false
+	}
+	// unix seconds stay below 1e11 until the year 5138
+	if number < 1e11 {
+		return time.UnixMilli(int64(number * 1000)), true
+	}
+	return time.UnixMilli(int64(number)), true
+}
+
+// describe formats the range for --explain
+func (r *attributeRange) describe() string {
+	switch {
+	case r.until.IsZero():
+		return fmt.Sprintf("%v at or after %v", r.attribute, r.since.Format(time.RFC3339))
+	case r.since.IsZero():
+		return fmt.Sprintf("%v before %v", r.attribute, r.until.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("%v from %v to %v", r.attribute, r.since.Format(time.RFC3339), r.until.Format(time.RFC3339))
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttributeTime(t *testing.T) {
+	expected := time.Date(2023, time.August, 14, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value any
+		ok    bool
+	}{
+		{name: "RFC3339", value: "2023-08-14T08:00:00Z", ok: true},
+		{name: "RFC3339 offset", value: "2023-08-14T10:00:00+02:00", ok: true},
+		{name: "seconds string", value: "1692000000", ok: true},
+		{name: "milliseconds string", value: "1692000000000", ok: true},
+		{name: "seconds float64", value: float64(1692000000), ok: true},
+		{name: "milliseconds float64", value: float64(1692000000000), ok: true},
+		{name: "seconds int64", value: int64(1692000000), ok: true},
+		{name: "seconds int", value: 1692000000, ok: true},
+		{name: "milliseconds json.Number", value: json.Number("1692000000000"), ok: true},
+		{name: "not a time", value: "yesterday"},
+		{name: "invalid json.Number", value: json.Number("x")},
+		{name: "bool", value: true},
+		{name: "missing", value: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, ok := attributeTime(test.value)
+			assert.Equal(t, test.ok, ok)
+			if test.ok {
+				assert.True(t, expected.Equal(actual), "expected %v, got %v", expected, actual)
+			}
+		})
+	}
+}
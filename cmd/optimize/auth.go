@@ -0,0 +1,45 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/cmd/uql"
+	"github.com/cisco-open/fsoc/cmdkit"
+)
+
+// authExitCode is the exit status of optimize commands failing because UQL rejected the credentials, distinct from
+// the status 1 of other failures so that scripts can prompt for a login rather than report a broken query
+const authExitCode = 3
+
+// surfaceAuthErrors wraps the RunE of the command and its subcommands so that UQL authentication failures end
+// fsoc with authExitCode
+func surfaceAuthErrors(command *cobra.Command) {
+	if runE := command.RunE; runE != nil {
+		command.RunE = func(cmd *cobra.Command, args []string) error {
+			err := runE(cmd, args)
+			if authErr := (uql.AuthError{}); errors.As(err, &authErr) {
+				return cmdkit.ExitError{Code: authExitCode, Err: err}
+			}
+			return err
+		}
+	}
+	for _, subcommand := range command.Commands() {
+		surfaceAuthErrors(subcommand)
+	}
+}
@@ -17,8 +17,16 @@ package optimize
 import (
 	"fmt"
 	"reflect"
+	"strconv"
+	"time"
 
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/cmd/uql"
+	"github.com/cisco-open/fsoc/cmdkit/term"
 	"github.com/cisco-open/fsoc/config"
+	"github.com/cisco-open/fsoc/output"
 )
 
 // sliceToMap converts a list of lists (slice [][2]any) to a dictionary for table output jq support
@@ -52,6 +60,20 @@ func sliceToMap(slice [][]any) (map[string]any, error) {
 	return results, nil
 }
 
+// parseSetting reads a numeric attribute value which may be reported as a number or a string
+func parseSetting(value any) (float64, bool) {
+	switch typed := value.(type) {
+	case float64:
+		return typed, true
+	case int:
+		return float64(typed), true
+	case string:
+		parsed, err := strconv.ParseFloat(typed, 64)
+		return parsed, err == nil
+	}
+	return 0, false
+}
+
 func setNestedMap(baseMap map[string]interface{}, keys []string, value interface{}) {
 	if len(keys) == 1 {
 		baseMap[keys[0]] = value
@@ -82,3 +104,57 @@ func checkHardBlockers(b *Blockers) bool {
 	}
 	return false
 }
+
+// startDebugTiming instruments the UQL client used by the optimize commands to record query timings.
+// The returned function restores the original client and prints the timing summary to stderr
+func startDebugTiming(cmd *cobra.Command) func() {
+	original := uql.ClientV1
+	timingClient := uql.NewTimingClient(original)
+	uql.ClientV1 = timingClient
+	return func() {
+		uql.ClientV1 = original
+		var total time.Duration
+		for _, timing := range timingClient.Timings() {
+			cmd.PrintErrf("%v\n", timing)
+			total += timing.Duration
+		}
+		cmd.PrintErrf("UQL total: %v\n", total.Round(time.Millisecond))
+	}
+}
+
+// printNoResults displays a message explaining that no results were found. With --quiet, the message is
+// written to stderr so that stdout carries only the structured output. With a machine-readable output format,
+// the message is also written to stderr and an empty list payload is output so that scripts can still parse it.
+// With the prometheus, html and markdown formats, the message is written to stderr and nothing is output
+func printNoResults(cmd *cobra.Command, s string) {
+	format, _ := cmd.Flags().GetString("output")
+	if format == "json" || format == "json-compact" || format == "yaml" {
+		cmd.PrintErr(s)
+		output.PrintCmdOutput(cmd, struct {
+			Items []any `json:"items"`
+			Total int   `json:"total"`
+		}{Items: []any{}, Total: 0})
+		return
+	}
+	if quiet, _ := cmd.Flags().GetBool("quiet"); quiet || format == prometheusOutputFormat || format == "html" || format == "markdown" {
+		// keep the status out of documents meant to be shared or rendered, e.g., with --output-file
+		cmd.PrintErr(s)
+		return
+	}
+	output.PrintCmdStatus(cmd, s)
+}
+
+// confirmPagination asks the user whether to continue retrieving pages after a large first page, returning an error if
+// the user declines. Unattended runs, whose input is not a terminal to ask on, such as cron jobs or CI pipelines,
+// retrieve all pages as they did before the prompt existed, with a warning
+func confirmPagination(cmd *cobra.Command, rowCount int) error {
+	if !term.IsTerminal(cmd.InOrStdin()) {
+		log.Warnf("The first page returned %v events and more pages are available, retrieving them all; use --count to limit them", rowCount)
+		return nil
+	}
+	return confirmation{
+		question:   fmt.Sprintf("The first page returned %v events and more pages are available. Continue retrieving?", rowCount),
+		unattended: fmt.Sprintf("the first page returned %v events and more pages are available; use --yes to retrieve them all or --count to limit them", rowCount),
+		declined:   "retrieval of further pages was not confirmed",
+	}.ask(cmd, false)
+}
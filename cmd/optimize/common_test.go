@@ -0,0 +1,46 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintNoResults(t *testing.T) {
+	tests := []struct {
+		format string
+		stdout string
+		stderr string
+	}{
+		{format: "table", stdout: "No events\n"},
+		{format: "html", stderr: "No events\n"},
+		{format: "markdown", stderr: "No events\n"},
+		{format: prometheusOutputFormat, stderr: "No events\n"},
+	}
+	for _, test := range tests {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("output", test.format, "")
+		var stdout, stderr bytes.Buffer
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&stderr)
+		printNoResults(cmd, "No events\n")
+		assert.Equal(t, test.stdout, stdout.String(), test.format)
+		assert.Equal(t, test.stderr, stderr.String(), test.format)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/cmdkit/term"
+)
+
+// confirmation describes the prompt of a potentially impactful action that the user confirms interactively
+// unless --yes is given
+type confirmation struct {
+	// question is prompted on stderr and answered with y or yes
+	question string
+
+	// unattended is the error returned when the input is not a terminal to prompt on, it should mention --yes
+	unattended string
+
+	// declined is the error returned when the user doesn't confirm
+	declined string
+}
+
+// addYesFlag adds the --yes/-y flag skipping the confirmations of the command
+func addYesFlag(cmd *cobra.Command, yes *bool, usage string) {
+	cmd.Flags().BoolVarP(yes, "yes", "y", false, usage)
+}
+
+// ask prompts for the confirmation unless yes is set. Rather than waiting for an answer that cannot come, an error is
+// returned right away if the input is not a terminal; an error is also returned if the user declines
+func (c confirmation) ask(cmd *cobra.Command, yes bool) error {
+	if yes {
+		return nil
+	}
+	in := cmd.InOrStdin()
+	if !term.IsTerminal(in) {
+		return errors.New(c.unattended)
+	}
+	cmd.PrintErrf("%v [y/N] ", c.question)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+		return errors.New(c.declined)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/cisco-open/fsoc/cmd/uql"
+)
+
+// savedCursor is the persisted form of a UQL pagination cursor, allowing an interrupted export to be resumed
+type savedCursor struct {
+	Rel  string `json:"rel"`
+	Href string `json:"href"`
+}
+
+// saveCursor writes the dataset's link with the given rel to the cursor file, replacing its previous content
+func saveCursor(path string, dataSet *uql.DataSet, rel string) error {
+	link, ok := dataSet.Links[rel]
+	if !ok {
+		return fmt.Errorf("dataset %v has no %q link to save", dataSet.Name, rel)
+	}
+	data, err := json.Marshal(savedCursor{Rel: rel, Href: link.Href})
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("os.WriteFile: %w", err)
+	}
+	return nil
+}
+
+// loadCursor reads a cursor file written by saveCursor and returns a dataset carrying only the saved link,
+// suitable to be passed to uql.UqlClient.ContinueQuery
+func loadCursor(path string) (*uql.DataSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+	var cursor savedCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("cursor file %q could not be parsed: %w", path, err)
+	}
+	if cursor.Rel == "" || cursor.Href == "" {
+		return nil, fmt.Errorf("cursor file %q does not contain a cursor", path)
+	}
+	dataSet := &uql.DataSet{Links: map[string]uql.Link{cursor.Rel: {Href: cursor.Href}}}
+	return dataSet, nil
+}
+
+// clearCursor removes the cursor file once there is nothing left to resume
+func clearCursor(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"time"
+)
+
+// eventIdAttribute is used as the event identity when present, otherwise identity is derived from
+// timestamp, event type and optimizer ID
+const eventIdAttribute = "appd.event.id"
+
+// followDedupCapacity bounds the number of recent event keys remembered when following
+const followDedupCapacity = 10000
+
+// eventDeduplicator remembers the keys of recently emitted events so that rows returned more than once
+// by overlapping follow cursor windows are only printed once. Memory is capped by a fixed size ring of keys;
+// once full, the oldest key is forgotten for each new one recorded
+type eventDeduplicator struct {
+	ring []string
+	next int
+	seen map[string]struct{}
+}
+
+func newEventDeduplicator(capacity int) *eventDeduplicator {
+	return &eventDeduplicator{
+		ring: make([]string, capacity),
+		seen: make(map[string]struct{}, capacity),
+	}
+}
+
+// filter returns the rows which have not been seen before and records them as seen.
+// A nil deduplicator passes all rows through unchanged
+func (d *eventDeduplicator) filter(rows []EventsRow) []EventsRow {
+	if d == nil {
+		return rows
+	}
+	results := make([]EventsRow, 0, len(rows))
+	for _, row := range rows {
+		key := eventKey(row)
+		if _, ok := d.seen[key]; ok {
+			continue
+		}
+		d.add(key)
+		results = append(results, row)
+	}
+	return results
+}
+
+func (d *eventDeduplicator) add(key string) {
+	if evicted := d.ring[d.next]; evicted != "" {
+		delete(d.seen, evicted)
+	}
+	d.ring[d.next] = key
+	d.seen[key] = struct{}{}
+	d.next = (d.next + 1) % len(d.ring)
+}
+
+func eventKey(row EventsRow) string {
+	if id, ok := row.EventAttributes[eventIdAttribute]; ok && id != nil && id != "" {
+		return fmt.Sprintf("id:%v", id)
+	}
+	return fmt.Sprintf("%v|%v|%v",
+		row.Timestamp.Format(time.RFC3339Nano),
+		row.EventAttributes["appd.event.type"],
+		row.EventAttributes["optimize.optimization.optimizer_id"],
+	)
+}
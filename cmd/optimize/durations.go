@@ -0,0 +1,64 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// spanNumberAttributes identify which optimization, stage or experiment an event belongs to
+var spanNumberAttributes = []string{
+	"optimize.optimization.num",
+	"optimize.stage.num",
+	"optimize.experiment.num",
+}
+
+// annotateDurations sets the Duration of each ended (or completed) event to the time elapsed since its matching
+// started event, paired by event type prefix, optimizer ID and the optimization/stage/experiment numbers.
+// Rows are expected in ascending timestamp order; unmatched events are left without a Duration
+func annotateDurations(rows []EventsRow) {
+	started := make(map[string]time.Time)
+	for i := range rows {
+		event := eventType(rows[i])
+		if base, ok := strings.CutSuffix(event, "_started"); ok {
+			started[spanKey(base, rows[i])] = rows[i].Timestamp
+			continue
+		}
+		base, ok := strings.CutSuffix(event, "_ended")
+		if !ok {
+			base, ok = strings.CutSuffix(event, "_completed")
+		}
+		if !ok {
+			continue
+		}
+		key := spanKey(base, rows[i])
+		if startTime, ok := started[key]; ok {
+			rows[i].Duration = rows[i].Timestamp.Sub(startTime).Round(time.Second).String()
+			delete(started, key)
+		}
+	}
+}
+
+func spanKey(base string, row EventsRow) string {
+	key := fmt.Sprintf("%v|%v", base, row.EventAttributes["optimize.optimization.optimizer_id"])
+	for _, attr := range spanNumberAttributes {
+		if num, ok := row.EventAttributes[attr]; ok {
+			key += fmt.Sprintf("|%v=%v", attr, num)
+		}
+	}
+	return key
+}
@@ -1,21 +1,22 @@
 package optimize
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"os/signal"
-	"strconv"
+	"slices"
+	"sort"
 	"strings"
 	"syscall"
-	"text/template"
 	"time"
 
 	"github.com/apex/log"
 	"github.com/spf13/cobra"
 
 	"github.com/cisco-open/fsoc/cmd/uql"
+	"github.com/cisco-open/fsoc/cmdkit/interrupt"
 	"github.com/cisco-open/fsoc/output"
 )
 
@@ -43,6 +44,12 @@ var progressEvents = []string{
 	"experiment_progress",
 }
 
+// maxLimitsCount is the largest count UQL accepts in a LIMITS clause; larger counts are retrieved by pagination
+const maxLimitsCount = 1000
+
+// allEventsToken is the --events value standing for the union of defaultEvents and progressEvents
+const allEventsToken = "all"
+
 func init() {
 	// TODO move this logic to optimize root when implementing unit tests
 	optimizeCmd.AddCommand(NewCmdEvents())
@@ -50,27 +57,107 @@ func init() {
 }
 
 type eventsFlags struct {
-	clusterId      string
-	namespace      string
-	workloadName   string
-	optimizerId    string
-	since          string
-	until          string
-	count          int
-	follow         bool
-	followInterval time.Duration
-	solutionName   string
+	clusterId         string
+	namespace         string
+	workloadName      string
+	optimizerId       string
+	optimizerIdPrefix string
+	optimizerIdFile   string
+	since             string
+	until             string
+	count             int
+	follow            bool
+	followInterval    time.Duration
+	followMaxDuration time.Duration
+	followMaxInterval time.Duration
+	followReconnects  int
+	solutionName      string
+	debugTiming       bool
+	has               []string
+	missing           []string
+	sortBy            string
+	sortDesc          bool
+	window            string
+	timeRange         string
+	timezone          string
+	failOnEmpty       bool
+	aliases           []string
+	aliasMap          attributeAliases
+	redact            []string
+	redactions        attributeRedactions
+	attrSince         []string
+	attrUntil         []string
+	attrRanges        attributeRanges
+	precision         int
+	stats             bool
+	retries           *retryBudget
+	interactive       bool
+	filterProfile     string
+	explain           bool
+	entityFilter      string
+	principal         string
+	principalAttr     string
+	normalizeIds      bool
+	units             []string
+	unitConversions   attributeUnits
+	resolvedIds       []string // optimizer IDs resolved from the filters, see filteredOptimizerIds
+	resolver          optimizerResolver
 }
 
 type eventsCmdFlags struct {
 	eventsFlags
 	includeProgress bool
 	events          []string
+	noDedup         bool
+	snapshotFile    string
+	snapshot        *eventSnapshot
+	untilEvent      string
+	stop            *followStop
+	cursorFile      string
+	resume          bool
+	summary         bool
+	countOnly       bool
+	experiments     bool
+	fromFile        string
+	legend          bool
+	compareWith     string
+	listTypes       bool
+	pagedReview     bool
+	flatten         bool
+	pageSize        int
+	onlyProgress    bool
+	noProgress      bool
+	excludeProgress bool
+	durations       bool
+	followEach      bool
+	progressPeriod  time.Duration
+	yes             bool
+	confirmAbove    int
+	sinceLatestReco bool
+	outputDir       string
+	sqlitePath      string
+	addSequence     bool
+	stableOrder     bool
+	sequencer       eventSequencer
+	annotateStages  bool
+	stages          eventStages
+	stream          bool
+	streamer        *eventStream
+	relativeTime    bool
+	excludeEvents   []string
 }
 
 type EventsRow struct {
+	Seq             int `json:",omitempty" yaml:",omitempty"`
 	Timestamp       time.Time
 	EventAttributes map[string]any
+	IsProgress      bool
+	Duration        string `json:",omitempty" yaml:",omitempty"`
+	Stage           string `json:",omitempty" yaml:",omitempty"`
+	Age             string `json:",omitempty" yaml:",omitempty"`
+	OptNamespace    string `json:",omitempty" yaml:",omitempty"`
+	OptName         string `json:",omitempty" yaml:",omitempty"`
+	OptUUID         string `json:",omitempty" yaml:",omitempty"`
 }
 
 type recommendationRow struct {
@@ -81,21 +168,28 @@ type recommendationRow struct {
 }
 
 func NewCmdEvents() *cobra.Command {
-	var flags eventsCmdFlags
+	return newCmdEvents(&eventsCmdFlags{})
+}
+
+// newCmdEvents returns the events command parsing its flags into flags, so that tests can inspect them
+func newCmdEvents(flags *eventsCmdFlags) *cobra.Command {
 	command := &cobra.Command{
 		Use:   "events",
 		Short: "Retrieve event logs for a given optimization/workload. Useful for monitoring and debug",
 		Example: `  fsoc optimize events
   fsoc optimize events --since -7d until 2023-07-31
   fsoc optimize events --events="experiment_deployment_started,experiment_deployment_completed"
+  fsoc optimize events --events all
   fsoc optimize events --optimizer-id namespace-name-00000000-0000-0000-0000-000000000000 --count 5
   fsoc optimize events --namespace some-namespace --cluster-id 00000000-0000-0000-0000-000000000000
-  fsoc optimize events --workload-name some-workload`,
-		RunE:             listEvents(&flags),
+  fsoc optimize events --workload-name some-workload
+  fsoc optimize events --optimizer-id-prefix namespace-name-
+  fsoc optimize events --namespace some-namespace --output-dir ./events -o json`,
+		RunE:             flushOnInterrupt(flags, listEvents(flags)),
 		TraverseChildren: true,
 		Annotations: map[string]string{
-			output.TableFieldsAnnotation:  "OptimizerId: .EventAttributes[\"optimize.optimization.optimizer_id\"], EventType: .EventAttributes[\"appd.event.type\"], Timestamp: .Timestamp",
-			output.DetailFieldsAnnotation: "OptimizerId: .EventAttributes[\"optimize.optimization.optimizer_id\"], EventType: .EventAttributes[\"appd.event.type\"], Timestamp: .Timestamp, Attributes: .EventAttributes",
+			output.TableFieldsAnnotation:  "OptimizerId: .EventAttributes[\"optimize.optimization.optimizer_id\"], EventType: .EventAttributes[\"appd.event.type\"], Progress: .IsProgress, Timestamp: .Timestamp",
+			output.DetailFieldsAnnotation: "OptimizerId: .EventAttributes[\"optimize.optimization.optimizer_id\"], EventType: .EventAttributes[\"appd.event.type\"], Progress: .IsProgress, Timestamp: .Timestamp, Duration: .Duration, Attributes: .EventAttributes",
 		},
 	}
 
@@ -106,211 +200,634 @@ func NewCmdEvents() *cobra.Command {
 	command.MarkFlagsMutuallyExclusive("optimizer-id", "cluster-id")
 	command.MarkFlagsMutuallyExclusive("optimizer-id", "namespace")
 	command.MarkFlagsMutuallyExclusive("optimizer-id", "workload-name")
+	command.Flags().StringVarP(&flags.optimizerIdPrefix, "optimizer-id-prefix", "", "", "Retrieve events for the optimizers whose ID starts with the given prefix, e.g., namespace-name-")
+	command.MarkFlagsMutuallyExclusive("optimizer-id-prefix", "optimizer-id")
+	command.Flags().StringVarP(&flags.entityFilter, "entity-filter", "", "", "Retrieve events of the optimizations matching a UQL predicate on the optimization entities, combined with the other filters by AND, e.g., \"attributes(k8s.cluster.name) = 'prod'\"")
+	command.MarkFlagsMutuallyExclusive("entity-filter", "optimizer-id")
+	command.Flags().BoolVarP(&flags.interactive, "interactive", "", false, "Prompt to select among the optimizers matching --namespace, --workload-name or --optimizer-id-prefix when more than one matches")
+	command.MarkFlagsMutuallyExclusive("interactive", "optimizer-id")
+	command.Flags().StringVarP(&flags.principal, "principal", "", "", "Retrieve events triggered by the given principal ID, combined with the other filters by AND")
+	command.Flags().StringVarP(&flags.principalAttr, "principal-attr", "", defaultPrincipalAttribute, "Attribute holding the principal ID matched by --principal, should the events carry it under another name")
+	command.Flags().StringVarP(&flags.optimizerIdFile, "optimizer-id-file", "", "", "Retrieve events for the optimizers listed in the given file, one ID per line, skipping blank lines and lines starting with #. Combines with --optimizer-id")
+	for _, flag := range []string{"namespace", "workload-name", "optimizer-id-prefix", "entity-filter", "interactive"} {
+		command.MarkFlagsMutuallyExclusive("optimizer-id-file", flag)
+	}
 
 	command.Flags().BoolVarP(&flags.includeProgress, "include-progress", "p", false, "Include progress events in query and output")
-	command.Flags().StringSliceVarP(&flags.events, "events", "e", defaultEvents, "Customize the types of events to be retrieved")
-	command.MarkFlagsMutuallyExclusive("include-progress", "events")
+	command.Flags().StringSliceVarP(&flags.events, "events", "e", defaultEvents, fmt.Sprintf("Customize the types of events to be retrieved; %q retrieves the default and progress events. Only %q may be combined with --include-progress", allEventsToken, allEventsToken))
+	command.Flags().StringSliceVarP(&flags.excludeEvents, "exclude-events", "", nil, "Event types to leave out of those retrieved with --events and --include-progress, e.g., --events all --exclude-events experiment_progress")
+	command.Flags().BoolVarP(&flags.onlyProgress, "only-progress", "", false, "Only output progress events")
+	command.Flags().BoolVarP(&flags.noProgress, "no-progress", "", false, "Only output lifecycle events, omitting progress events")
+	command.MarkFlagsMutuallyExclusive("only-progress", "no-progress")
+	command.Flags().BoolVarP(&flags.excludeProgress, "exclude-progress", "", false, "Omit the events whose event type is a progress event type from the output, e.g., when replaying with --from-file events exported with --include-progress. May only be combined with --include-progress when replaying")
+	command.MarkFlagsMutuallyExclusive("exclude-progress", "only-progress")
+
+	command.Flags().StringSliceVarP(&flags.has, "has", "", nil, "Only output events carrying the given attribute. May be repeated, evaluated client-side after retrieval")
+	command.Flags().StringSliceVarP(&flags.missing, "missing", "", nil, "Only output events not carrying the given attribute. May be repeated, evaluated client-side after retrieval")
+	command.Flags().StringSliceVarP(&flags.attrSince, "attr-since", "", nil, "Only output events whose time attribute is at or after the given time, in the form attribute=time with an RFC3339 time or unix timestamp. Unlike --since, which bounds when events were emitted, this bounds a time carried by the events, e.g., a measurement window start. May be repeated, evaluated client-side after retrieval")
+	command.Flags().StringSliceVarP(&flags.attrUntil, "attr-until", "", nil, "Only output events whose time attribute is before the given time, in the form attribute=time, see --attr-since. May be repeated, evaluated client-side after retrieval")
+
+	command.Flags().StringVarP(&flags.sortBy, "sort-by", "", "", "Sort the output events by the given attribute name or by Timestamp, placing events missing the attribute last")
+	command.Flags().BoolVarP(&flags.sortDesc, "sort-desc", "", false, "Sort in descending order when used with --sort-by")
+
+	command.Flags().StringVarP(&flags.since, "since", "s", "", "Retrieve events contained in the time interval starting at a relative or exact time, a calendar anchor such as @week or a unix timestamp in seconds or milliseconds. (default: -1h)")
+	command.Flags().StringVarP(&flags.until, "until", "u", "", "Retrieve events contained in the time interval ending at a relative or exact time, a calendar anchor such as @day or a unix timestamp in seconds or milliseconds. (default: now)")
+	command.Flags().BoolVarP(&flags.failOnEmpty, "fail-on-empty", "", false, "Exit with an error when no optimizations match the filters or no events are found")
+	command.Flags().IntVarP(&flags.precision, "precision", "", -1, "Round numeric attributes such as the recommended settings to the given number of decimal places")
+	command.Flags().StringSliceVarP(&flags.units, "unit", "", nil, "Convert a recommended setting to the given unit in the output, in the form resource=unit, e.g., cpu=millicores or memory=MiB. Units for cpu: cores, millicores; for memory: bytes, KiB, MiB, GiB, TiB. May be repeated")
+	command.Flags().StringSliceVarP(&flags.aliases, "alias", "", nil, "Rename an attribute in the output, in the form attribute=alias. May be repeated")
+	command.Flags().StringSliceVarP(&flags.redact, "redact", "", nil, "Mask the value of an attribute in the output with ***. Accepts globs such as optimize.principal.* and may be repeated. Applies to presentation only, the query is unaffected")
+	command.Flags().StringVarP(&flags.window, "window", "", "", fmt.Sprintf("Retrieve events contained in a named local time window instead of --since/--until. One of: %v", strings.Join(windowPresets, ", ")))
+	command.Flags().BoolVarP(&flags.explain, "explain", "", false, "Describe what the command would do with the given flags instead of running it")
+	command.Flags().StringVarP(&flags.filterProfile, "filter-profile", "", "", fmt.Sprintf("Apply the cluster, namespace, workload and time flags of the named profile in %v, unless given on the command line", filterProfilesFile))
+	command.MarkFlagsMutuallyExclusive("window", "since")
+	command.MarkFlagsMutuallyExclusive("window", "until")
+	command.Flags().StringVarP(&flags.timeRange, "range", "", "", "Retrieve events contained in the time interval of the form since..until instead of --since/--until, e.g., -7d..-1d; an empty until means now")
+	command.Flags().StringVarP(&flags.timezone, "timezone", "", "", "IANA time zone, e.g., Europe/Paris, in which --window presets and the calendar anchors @day, @week and @month of --since, --until and --range are resolved to midnight, weeks starting on Monday. (default: local time zone)")
+	for _, flag := range []string{"since", "until", "window"} {
+		command.MarkFlagsMutuallyExclusive("range", flag)
+	}
+	command.Flags().BoolVarP(&flags.sinceLatestReco, "since-latest-recommendation", "", false, "Retrieve events since the newest verified recommendation of the --optimizer-id")
+	command.MarkFlagsMutuallyExclusive("since-latest-recommendation", "since")
+	command.MarkFlagsMutuallyExclusive("since-latest-recommendation", "window")
+	command.MarkFlagsMutuallyExclusive("since-latest-recommendation", "range")
+	command.Flags().IntVarP(&flags.count, "count", "", -1, fmt.Sprintf("Limit the number of events retrieved to the specified count. Counts above %v are retrieved across multiple pages", maxLimitsCount))
 
-	command.Flags().StringVarP(&flags.since, "since", "s", "", "Retrieve events contained in the time interval starting at a relative or exact time. (default: -1h)")
-	command.Flags().StringVarP(&flags.until, "until", "u", "", "Retrieve events contained in the time interval ending at a relative or exact time. (default: now)")
-	command.Flags().IntVarP(&flags.count, "count", "", -1, "Limit the number of events retrieved to the specified count")
+	command.Flags().IntVarP(&flags.pageSize, "page-size", "", -1, "Number of events to request per page from UQL; unlike --count, all pages are retrieved")
 
 	command.Flags().BoolVarP(&flags.follow, "follow", "f", false, "Follow the events as they are produced")
 	command.Flags().DurationVarP(&flags.followInterval, "follow-interval", "t", time.Second*60, "Duration between requests to UQL when following events")
 	command.MarkFlagsMutuallyExclusive("follow", "count")
+	command.Flags().DurationVarP(&flags.followMaxInterval, "follow-max-interval", "", 0, "Double the duration between follow requests while no new events arrive, up to the given ceiling. The --follow-interval is restored once events arrive (default: no backoff)")
+	command.Flags().DurationVarP(&flags.followMaxDuration, "follow-max-duration", "", 0, "Stop following events once the given duration has elapsed, e.g., 10m (default: follow until interrupted)")
+	command.Flags().IntVarP(&flags.followReconnects, "follow-max-reconnects", "", 5, "Number of consecutive transient failures of follow requests, such as network errors or HTTP 503 responses, after which following stops. The cursor is continued again after --follow-interval, doubling the wait for each consecutive failure up to --follow-max-interval; failures such as rejected credentials or an invalid cursor stop following at once. 0 disables reconnecting")
+	command.Flags().DurationVarP(&flags.progressPeriod, "progress-follow-interval", "", 0, "When following lifecycle and progress events, e.g., with --include-progress, follow the progress events with their own cursor at the given interval, while lifecycle events are followed at --follow-interval")
+	command.Flags().BoolVarP(&flags.followEach, "follow-per-optimizer", "", false, "When following events filtered by namespace, workload name or optimizer ID prefix, follow each matching optimizer with its own cursor")
+	command.Flags().BoolVarP(&flags.noDedup, "no-dedup", "", false, "Disable removal of duplicate events returned by overlapping follow requests")
+	command.MarkFlagsMutuallyExclusive("follow-per-optimizer", "no-dedup")
+	command.MarkFlagsMutuallyExclusive("follow-per-optimizer", "progress-follow-interval")
+	command.Flags().StringVarP(&flags.untilEvent, "until-event", "", "", "Stop following events, with exit status 0, once an event of the given type, e.g., optimization_ended, was printed for each followed optimizer")
+	command.Flags().StringVarP(&flags.snapshotFile, "snapshot-file", "", "", "When following events, also maintain the given file with all the events printed so far, in the selected output format. The file is replaced atomically after each batch")
+
+	command.Flags().BoolVarP(&flags.summary, "summary", "", false, "Output the number of events per event type instead of the events. Counts are aggregated by UQL when possible")
+	command.MarkFlagsMutuallyExclusive("summary", "follow")
+	command.Flags().BoolVarP(&flags.countOnly, "count-only", "", false, "Output only the total number of matching events. Counts are aggregated by UQL when possible")
+	command.MarkFlagsMutuallyExclusive("count-only", "follow")
+	command.MarkFlagsMutuallyExclusive("count-only", "summary")
+	command.Flags().BoolVarP(&flags.experiments, "experiments", "", false, "Output one row per experiment with its start, end and duration, folded from the experiment_started and experiment_ended events")
+	command.MarkFlagsMutuallyExclusive("experiments", "follow")
+	command.MarkFlagsMutuallyExclusive("experiments", "summary")
+	command.MarkFlagsMutuallyExclusive("experiments", "count-only")
+
+	command.Flags().StringVarP(&flags.outputDir, "output-dir", "", "", "Write the events of each optimizer to its own file in the given directory, named after the optimizer ID, in the selected output format")
+	command.MarkFlagsMutuallyExclusive("output-dir", "follow")
+	command.MarkFlagsMutuallyExclusive("output-dir", "summary")
+	command.MarkFlagsMutuallyExclusive("output-dir", "count-only")
+	command.MarkFlagsMutuallyExclusive("output-dir", "experiments")
+	command.Flags().StringVarP(&flags.fromFile, "from-file", "", "", "Output the events previously exported with -o json or json-compact to the given file, or - for stdin, instead of retrieving them. Filters of the query are not applied")
+
+	command.Flags().BoolVarP(&flags.flatten, "flatten", "", false, "For JSON output, promote each event attribute to a top-level key prefixed with \""+flattenedAttributePrefix+"\"")
+
+	command.Flags().BoolVarP(&flags.durations, "durations", "", false, "Annotate ended events with the duration since their matching started event")
+	command.Flags().BoolVarP(&flags.addSequence, "add-sequence", "", false, "Number the events of each optimizer in timestamp order, shown as the leading Seq column")
+	command.Flags().BoolVarP(&flags.legend, "legend", "", false, "Precede human output with a legend describing the event types present")
+	command.Flags().BoolVarP(&flags.normalizeIds, "normalize-optimizer-id", "", false, "Split the optimizer ID of each event into its OptNamespace, OptName and OptUUID components, shown as columns and fields")
+	command.Flags().BoolVarP(&flags.annotateStages, "annotate-stages", "", false, "Group the events under the stage started by the latest stage_started event of their optimizer, shown as the leading Stage column of human output")
+	command.Flags().BoolVarP(&flags.relativeTime, "relative-time", "", false, "Add an Age column to human output with how long ago each event occurred, e.g., 12m ago, as of when it is printed. JSON and YAML output keep the absolute timestamps only")
+	command.Flags().BoolVarP(&flags.stableOrder, "stable-order", "", false, "Order events of identical timestamps by event type, then optimizer ID, so that the output is the same across runs")
+
+	command.Flags().IntVarP(&flags.confirmAbove, "confirm-threshold", "", 500, "Ask for confirmation before retrieving further pages when the first page holds more events than this and --count is not set; 0 disables. Without a terminal to ask on, all pages are retrieved with a warning")
+	addYesFlag(command, &flags.yes, "Continue retrieving pages without asking for confirmation")
+
+	command.Flags().StringVarP(&flags.cursorFile, "cursor-file", "", "", "Save the pagination cursor to the given file after each page so that an interrupted export can be resumed. Requires --stream, which writes the events of each page before its cursor is saved")
+	command.Flags().BoolVarP(&flags.resume, "resume", "", false, "Resume an interrupted export from the cursor saved in --cursor-file; only the remaining pages are retrieved")
 
 	command.Flags().StringVarP(&flags.solutionName, "solution-name", "", "optimize", "Intended for developer usage, overrides the name of the solution defining the FMM types for reading")
 	if err := command.LocalFlags().MarkHidden("solution-name"); err != nil {
 		log.Warnf("Failed to set events solution-name flag hidden: %v", err)
 	}
+	command.Flags().BoolVarP(&flags.debugTiming, "debug-timing", "", false, "Print a summary of UQL query timings to stderr on completion")
+	addStrictFlag(command)
+	command.Flags().BoolVarP(&flags.stats, "stats", "", false, "Print the number of rows and pages retrieved and the elapsed time to stderr after table output")
 
+	command.Flags().BoolVarP(&flags.listTypes, "list-types", "", false, "Output the event types present in the time interval for the given filters, with their number of events, instead of the events")
+	for _, flag := range []string{"events", "include-progress", "summary", "count-only", "experiments", "follow", "output-dir"} {
+		command.MarkFlagsMutuallyExclusive("list-types", flag)
+	}
+	command.Flags().StringVarP(&flags.compareWith, "compare-with", "", "", "Output the number of events per event type next to those of a second time interval of the form since..until, e.g., -2w..-1w")
+	for _, flag := range []string{"follow", "summary", "count-only", "experiments", "output-dir", "from-file", "count", "resume", "list-types"} {
+		command.MarkFlagsMutuallyExclusive("compare-with", flag)
+	}
+	command.Flags().BoolVarP(&flags.pagedReview, "paged-review", "", false, "Review the events newest first, one page of --page-size events at a time (default 25), pressing Enter to retrieve the next older page until the start of the time interval is reached")
+	for _, flag := range []string{"follow", "count", "summary", "count-only", "experiments", "output-dir", "from-file", "resume", "cursor-file", "compare-with", "list-types", "sort-by", "add-sequence"} {
+		command.MarkFlagsMutuallyExclusive("paged-review", flag)
+	}
+	// the query is not executed with --from-file, so the flags shaping it are rejected rather than ignored
+	for _, flag := range []string{"cluster-id", "namespace", "workload-name", "optimizer-id", "optimizer-id-prefix", "entity-filter", "since", "until", "window", "range", "follow", "resume", "cursor-file", "since-latest-recommendation", "page-size"} {
+		command.MarkFlagsMutuallyExclusive("from-file", flag)
+	}
+	command.Flags().BoolVarP(&flags.stream, "stream", "", false, "For json and json-compact output, write the events of each page as it is retrieved rather than all of them at the end, keeping the memory used by large exports bounded. The total follows the items; a failure part way leaves the output incomplete")
+	// these flags process the events as a whole, which streaming doesn't accumulate
+	for _, flag := range []string{"follow", "summary", "count-only", "experiments", "output-dir", "from-file", "compare-with", "paged-review", "list-types", "sort-by", "stable-order", "add-sequence", "durations", "annotate-stages", "legend"} {
+		command.MarkFlagsMutuallyExclusive("stream", flag)
+	}
+	if sqliteDriverLinked {
+		command.Flags().StringVarP(&flags.sqlitePath, "sqlite", "", "", "Insert the events into the events table of the SQLite database at the given path, creating it if needed, instead of printing them. Columns hold the timestamp, event type, optimizer ID, namespace, workload and the attributes as JSON")
+		for _, flag := range []string{"follow", "summary", "count-only", "experiments", "output-dir", "paged-review", "stream"} {
+			command.MarkFlagsMutuallyExclusive("sqlite", flag)
+		}
+	}
 	return command
 }
 
-type eventsTemplateValues struct {
-	Since  string
-	Until  string
-	Events string
-	Filter string
-	Limits string
-}
-
-var eventsTemplate = template.Must(template.New("").Parse(`
-{{ with .Since }}SINCE {{ . }}
-{{ end -}}
-{{ with .Until }}UNTIL {{ . }}
-{{ end -}}
-FETCH events(
-		{{ .Events }}
-	)
-	{{ with .Filter }}[{{ . }}]
-	{{ end -}}
-	{attributes, timestamp}
-{{ with .Limits }}LIMITS events.count({{ . }})
-{{ end -}}
-ORDER events.asc()
-`))
+type eventsQueryValues struct {
+	Since   string
+	Until   string
+	Events  []string
+	Filters []string
+	Limits  int  // per page, 0 for the default page size
+	Desc    bool // newest events first
+}
+
+func eventsQuery(queryVals eventsQueryValues) *uql.Query {
+	builder := uql.NewBuilder().
+		Since(queryVals.Since).
+		Until(queryVals.Until).
+		Fetch("events", queryVals.Events...).
+		Where(queryVals.Filters...).
+		Fields("attributes", "timestamp")
+	if queryVals.Limits > 0 {
+		builder.Limit("events", queryVals.Limits)
+	}
+	if queryVals.Desc {
+		return builder.OrderDesc("events").Build()
+	}
+	return builder.OrderAsc("events").Build()
+}
+
+// eventsBaseFilters returns the filters of the events query that don't constrain the optimizers
+func (flags *eventsCmdFlags) eventsBaseFilters() []string {
+	filterList := make([]string, 0, 2)
+	if flags.clusterId != "" {
+		filterList = append(filterList, uql.AttributeEquals("k8s.cluster.id", flags.clusterId))
+	}
+	if flags.principal != "" {
+		filterList = append(filterList, uql.AttributeEquals(flags.principalAttr, flags.principal))
+	}
+	return filterList
+}
+
+// buildEventsQueryValues returns the values of the events query for the flags, once the event types are expanded,
+// see expandEvents, along with the optimizer IDs the events are constrained to, see filteredOptimizerIds
+func buildEventsQueryValues(flags *eventsCmdFlags) (eventsQueryValues, []string, error) {
+	optimizerIds, err := flags.filteredOptimizerIds()
+	if err != nil {
+		return eventsQueryValues{}, nil, err
+	}
+	queryVals := eventsQueryValues{
+		Since: flags.since,
+		Until: flags.until,
+	}
+
+	fullyQualifiedEvents := make([]string, 0, len(flags.events))
+	for _, value := range flags.events {
+		fullyQualifiedEvents = append(fullyQualifiedEvents, fmt.Sprintf("%v:%v", flags.solutionName, value))
+	}
+	queryVals.Events = fullyQualifiedEvents
+
+	filterList := flags.eventsBaseFilters()
+	if optimizerIds != nil {
+		filterList = append(filterList, uql.AttributeIn("optimize.optimization.optimizer_id", optimizerIds))
+	} else if flags.optimizerId != "" {
+		filterList = append(filterList, uql.AttributeEquals("optimize.optimization.optimizer_id", flags.optimizerId))
+	}
+	queryVals.Filters = filterList
+
+	if flags.count != -1 {
+		if flags.count < 1 {
+			return eventsQueryValues{}, nil, errors.New("counts must be positive")
+		}
+		if flags.count <= maxLimitsCount {
+			queryVals.Limits = flags.count
+		}
+	}
+	if flags.pageSize != -1 {
+		if flags.pageSize < 1 || flags.pageSize > maxLimitsCount {
+			return eventsQueryValues{}, nil, fmt.Errorf("page sizes must be between 1 and %v", maxLimitsCount)
+		}
+		// with both set, pages no larger than the count are requested and pagination stops once count is reached
+		if flags.count == -1 || flags.pageSize < flags.count {
+			queryVals.Limits = flags.pageSize
+		}
+	}
+	return queryVals, optimizerIds, nil
+}
+
+// buildEventsQuery returns the UQL of the events query for the flags, see buildEventsQueryValues
+func buildEventsQuery(flags *eventsCmdFlags) (string, error) {
+	queryVals, _, err := buildEventsQueryValues(flags)
+	if err != nil {
+		return "", err
+	}
+	return eventsQuery(queryVals).Str, nil
+}
 
 func listEvents(flags *eventsCmdFlags) func(*cobra.Command, []string) error {
 	return func(cmd *cobra.Command, args []string) error {
-		// setup query
-		tempVals := eventsTemplateValues{
-			Since: flags.since,
-			Until: flags.until,
+		flags.retries = newRetryBudget(cmd)
+		responseWarnings.setStrict(cmd)
+		if err := flags.applyFilterProfile(cmd); err != nil {
+			return err
 		}
-
-		if flags.includeProgress {
-			flags.events = append(flags.events, progressEvents...)
+		if err := flags.checkEntityFilter(cmd); err != nil {
+			return err
 		}
-		fullyQualifiedEvents := make([]string, 0, len(flags.events))
-		for _, value := range flags.events {
-			fullyQualifiedEvents = append(fullyQualifiedEvents, fmt.Sprintf("%v:%v", flags.solutionName, value))
+		if err := flags.checkPrincipal(cmd); err != nil {
+			return err
 		}
-		tempVals.Events = strings.Join(fullyQualifiedEvents, ",\n		")
-
-		filterList := make([]string, 0, 2)
-		if flags.clusterId != "" {
-			filterList = append(filterList, fmt.Sprintf("attributes(k8s.cluster.id) = %q", flags.clusterId))
+		if err := flags.checkInteractive(cmd); err != nil {
+			return err
+		}
+		if err := flags.applyWindow(); err != nil {
+			return err
+		}
+		if flags.normalizeIds {
+			// before aliasing, which adjusts the output columns to the renamed attributes
+			addOptimizerIdColumns(cmd)
+		}
+		if err := flags.parseAliases(cmd); err != nil {
+			return err
 		}
-		if flags.optimizerId != "" {
-			filterList = append(filterList, fmt.Sprintf("attributes(optimize.optimization.optimizer_id) = %q", flags.optimizerId))
-		} else if flags.namespace != "" || flags.workloadName != "" {
-			optimizerIds, err := listOptimizations(&flags.eventsFlags)
+		if err := flags.parseRedactions(); err != nil {
+			return err
+		}
+		if err := flags.parseUnits(cmd); err != nil {
+			return err
+		}
+		if err := flags.parseAttributeRanges(); err != nil {
+			return err
+		}
+		if flags.follow && flags.until != "" {
+			return errors.New("--follow cannot be combined with --until; following implies an open-ended time interval that extends past now")
+		}
+		if flags.sinceLatestReco {
+			if flags.optimizerId == "" {
+				return errors.New("--since-latest-recommendation requires --optimizer-id")
+			}
+			since, found, err := latestRecommendationSince(&flags.eventsFlags)
 			if err != nil {
-				return fmt.Errorf("listOptimizations: %w", err)
+				return fmt.Errorf("latestRecommendationSince: %w", err)
 			}
-			if len(optimizerIds) < 1 {
-				output.PrintCmdStatus(cmd, "No optimization entities found matching the given criteria\n")
-				return nil
+			if found {
+				flags.since = since
+			} else {
+				log.Warnf("No verified recommendation found for optimizer %q, retrieving events from the default time interval", flags.optimizerId)
 			}
-			optIdStr := strings.Join(optimizerIds, "\", \"")
-			filterList = append(filterList, fmt.Sprintf("attributes(optimize.optimization.optimizer_id) IN [\"%v\"]", optIdStr))
 		}
-		tempVals.Filter = strings.Join(filterList, " && ")
-
-		if flags.count != -1 {
-			if flags.count > 1000 {
-				return errors.New("counts higher than 1000 are not supported")
+		if flags.followEach && !flags.follow {
+			return errors.New("--follow-per-optimizer requires --follow")
+		}
+		if flags.snapshotFile != "" {
+			if !flags.follow {
+				return errors.New("--snapshot-file requires --follow")
 			}
-			tempVals.Limits = strconv.Itoa(flags.count)
+			flags.snapshot = &eventSnapshot{path: flags.snapshotFile, flatten: flags.flatten}
 		}
-
-		var buff bytes.Buffer
-		if err := eventsTemplate.Execute(&buff, tempVals); err != nil {
-			return fmt.Errorf("eventsTemplate.Execute: %w", err)
+		if cmd.Flags().Changed("follow-max-duration") && (!flags.follow || flags.followMaxDuration <= 0) {
+			return errors.New("--follow-max-duration requires --follow and a positive duration")
 		}
-		query := buff.String()
-
-		// execute query, process results
-		resp, err := uql.ClientV1.ExecuteQuery(&uql.Query{Str: query})
-		if err != nil {
-			return fmt.Errorf("uql.ClientV1.ExecuteQuery: %w", err)
+		if cmd.Flags().Changed("follow-max-reconnects") && (!flags.follow || flags.followReconnects < 0) {
+			return errors.New("--follow-max-reconnects requires --follow and a count of at least 0")
 		}
-		if resp.HasErrors() {
-			log.Error("Execution of events query encountered errors. Returned data may not be complete!")
-			for _, e := range resp.Errors() {
-				log.Errorf("%s: %s", e.Title, e.Detail)
-			}
+		if cmd.Flags().Changed("follow-max-interval") && (!flags.follow || flags.followMaxInterval < flags.followInterval) {
+			return errors.New("--follow-max-interval requires --follow and a duration no shorter than --follow-interval")
+		}
+		if format, _ := cmd.Flags().GetString("output"); format == "html" && flags.follow {
+			// each followed batch would append another complete document
+			return errors.New("-o html writes a single report and cannot be combined with --follow")
 		}
+		if flags.excludeProgress && flags.includeProgress && flags.fromFile == "" {
+			return errors.New("--exclude-progress cannot be combined with --include-progress unless replaying events with --from-file; omit --include-progress to retrieve lifecycle events only")
+		}
+		if flags.resume && flags.cursorFile == "" {
+			return errors.New("--resume requires --cursor-file to locate the saved cursor")
+		}
+		if flags.cursorFile != "" && !flags.stream {
+			// otherwise the cursor would move past pages printed only at the end, which an interrupted run never prints
+			return errors.New("--cursor-file requires --stream, so that the events of each page are written before its cursor is saved")
+		}
+		if flags.debugTiming {
+			defer startDebugTiming(cmd)()
+		}
+		stats := startStats(&flags.eventsFlags)
+		defer stats.stop()
 
-		main_data_set := resp.Main()
-		if main_data_set == nil || len(main_data_set.Data) < 1 {
-			output.PrintCmdStatus(cmd, "No event results found for given input\n")
+		if flags.listTypes {
+			// the event types present are those with a count in the summary over all known event types
+			flags.events = []string{allEventsToken}
+			flags.summary = true
+		}
+		if err := flags.expandEvents(cmd); err != nil {
+			return err
+		}
+		if err := flags.checkProgressFollowInterval(cmd); err != nil {
+			return err
+		}
+		if flags.explain {
+			flags.explainEvents(cmd)
 			return nil
 		}
-		if len(main_data_set.Data[0]) < 1 {
-			return fmt.Errorf("main dataset %v first row has no columns", main_data_set.Name)
+		// setup query
+		flags.resolver = flags.commandResolver(cmd)
+		queryVals, optimizerIds, err := buildEventsQueryValues(flags)
+		if errors.Is(err, errNoOptimizations) {
+			return flags.noResults(cmd, "No optimization entities found matching the given criteria\n")
 		}
-
-		data_set, ok := main_data_set.Data[0][0].(*uql.DataSet)
-		if !ok {
-			return fmt.Errorf("main dataset %v first row first column (type %T) could not be converted to *uql.DataSet", main_data_set.Name, main_data_set.Data[0][0])
+		if err != nil {
+			return err
 		}
-		eventRows, err := extractEventsData(data_set)
+		baseFilters := flags.eventsBaseFilters()
+		stopIds := optimizerIds
+		if stopIds == nil && flags.optimizerId != "" {
+			stopIds = []string{flags.optimizerId}
+		}
+		stop, err := flags.newFollowStop(stopIds)
 		if err != nil {
-			return fmt.Errorf("extractEventsData: %w", err)
+			return err
 		}
+		flags.stop = stop
 
-		// handle pagination
-		next_ok := false
-		if data_set != nil {
-			_, next_ok = data_set.Links["next"]
+		if flags.compareWith != "" {
+			return flags.compareWindows(cmd, queryVals)
 		}
-		if flags.count != -1 {
-			// skip pagination if limits provided. Otherwise, we return the full result list (chunked into count per response)
-			// instead of constraining to count
-			next_ok = false
+		if flags.pagedReview {
+			return flags.reviewPages(cmd, queryVals)
 		}
-		if flags.follow {
-			// skip next cursor pagination on follow since the follow cursor contains the same data
-			next_ok = false
+
+		// let UQL aggregate the summary counts unless client-side limits or filters apply, see aggregatesCounts
+		if flags.summary && flags.aggregatesCounts() {
+			counts, err := queryEventTypeCounts(queryVals)
+			if err == nil {
+				printEventTypeCounts(cmd, counts)
+				return nil
+			}
+			log.Warnf("Aggregation of event counts by UQL failed, counting retrieved events instead: %v", err)
+		}
+		if flags.countOnly && flags.aggregatesCounts() {
+			counts, err := queryEventTypeCounts(queryVals)
+			if err == nil {
+				return flags.printEventCount(cmd, totalEventCount(counts))
+			}
+			log.Warnf("Aggregation of event counts by UQL failed, counting retrieved events instead: %v", err)
 		}
-		for page := 2; next_ok; page++ {
-			resp, err = uql.ClientV1.ContinueQuery(data_set, "next")
+
+		query := eventsQuery(queryVals)
+		if flags.streamer, err = flags.newEventStream(cmd); err != nil {
+			return err
+		}
+
+		eventRows := []EventsRow{}
+		retrieved := 0
+		pages := uql.Pages{Nested: true, Continue: flags.retries.continueQuery, Description: "events query", OnErrors: responseWarnings.onPageErrors("events query")}
+		processPage := func(page int, pageDataSet *uql.DataSet) (bool, error) {
+			newRows, err := extractEventsData(pageDataSet)
 			if err != nil {
-				return fmt.Errorf("page %v uql.ClientV1.ContinueQuery: %w", page, err)
+				return false, fmt.Errorf("page %v extractEventsData: %w", page, err)
 			}
-			if resp.HasErrors() {
-				log.Errorf("Continuation of events query (page %v) encountered errors. Returned data may not be complete!", page)
-				for _, e := range resp.Errors() {
-					log.Errorf("%s: %s", e.Title, e.Detail)
+			if flags.count != -1 && retrieved+len(newRows) > flags.count {
+				newRows = newRows[:flags.count-retrieved]
+			}
+			retrieved += len(newRows)
+			if flags.streamer != nil {
+				if err := flags.streamer.add(flags, newRows); err != nil {
+					return false, err
 				}
+			} else {
+				eventRows = append(eventRows, newRows...)
 			}
-			main_data_set := resp.Main()
-			if main_data_set == nil {
-				log.Errorf("Continuation of events query (page %v) has nil main data. Returned data may not be complete!", page)
-				break
+
+			if _, ok := pageDataSet.Links["next"]; !ok {
+				return false, nil
+			}
+			if flags.count != -1 && ((flags.pageSize == -1 && flags.count <= maxLimitsCount) || retrieved >= flags.count) {
+				// skip pagination if the count fits in the limits of a single page. Otherwise, pages are retrieved until
+				// the count has been accumulated
+				return false, nil
+			}
+			if flags.follow {
+				// skip next cursor pagination on follow since the follow cursor contains the same data
+				return false, nil
+			}
+			if page == 1 && flags.count == -1 && !flags.resume && !flags.yes && flags.confirmAbove > 0 && retrieved > flags.confirmAbove {
+				if err := confirmPagination(cmd, retrieved); err != nil {
+					return false, err
+				}
+			}
+			if flags.cursorFile != "" {
+				if err := saveCursor(flags.cursorFile, pageDataSet, "next"); err != nil {
+					return false, fmt.Errorf("page %v saveCursor: %w", page+1, err)
+				}
+			}
+			return true, nil
+		}
+
+		var data_set *uql.DataSet
+		if flags.fromFile != "" {
+			// re-render events exported earlier, the query is not executed
+			rows, err := readEventRows(cmd, flags.fromFile)
+			if err != nil {
+				return err
 			}
-			if len(main_data_set.Data) < 1 {
-				return fmt.Errorf("page %v main dataset %v has no rows", page, main_data_set.Name)
+			eventRows = rows
+		} else if flags.resume {
+			// continue from the cursor saved by an interrupted run, the query is not executed again
+			cursor, err := loadCursor(flags.cursorFile)
+			if err != nil {
+				return fmt.Errorf("loadCursor: %w", err)
+			}
+			data_set, err = pages.IterateFrom(cursor, 1, processPage)
+			if err != nil {
+				return err
 			}
-			if len(main_data_set.Data[0]) < 1 {
-				return fmt.Errorf("page %v main dataset %v first row has no columns", page, main_data_set.Name)
+		} else {
+			// execute query, process results
+			resp, err := uql.ClientV1.ExecuteQuery(query)
+			if err != nil {
+				return fmt.Errorf("uql.ClientV1.ExecuteQuery: %w", err)
 			}
-			data_set, ok = main_data_set.Data[0][0].(*uql.DataSet)
-			if !ok {
-				return fmt.Errorf("page %v main dataset %v first row first column (type %T) could not be converted to *uql.DataSet", page, main_data_set.Name, main_data_set.Data[0][0])
+			if err := responseWarnings.checkResponse(resp, "Execution", "events query"); err != nil {
+				return err
 			}
 
-			newRows, err := extractEventsData(data_set)
+			main_data_set := resp.Main()
+			if main_data_set == nil || len(main_data_set.Data) < 1 {
+				return flags.noResults(cmd, "No event results found for given input\n")
+			}
+			data_set, err = pages.Iterate(resp, processPage)
 			if err != nil {
-				return fmt.Errorf("page %v extractEventsData: %w", page, err)
+				return err
+			}
+		}
+		if flags.count != -1 && len(eventRows) > flags.count {
+			eventRows = eventRows[:flags.count]
+		}
+		if flags.cursorFile != "" {
+			// the export completed, nothing is left to resume
+			if err := clearCursor(flags.cursorFile); err != nil {
+				log.Warnf("Failed to remove cursor file %q: %v", flags.cursorFile, err)
 			}
-			eventRows = append(eventRows, newRows...)
-			_, next_ok = data_set.Links["next"]
+		}
+		if flags.streamer != nil {
+			if err := flags.streamer.close(); err != nil {
+				return err
+			}
+			stats.finish(cmd, flags.streamer.json.Count())
+			if flags.failOnEmpty && flags.streamer.json.Count() < 1 {
+				return errNoResults
+			}
+			return nil
 		}
 
-		output.PrintCmdOutput(cmd, struct {
-			Items []EventsRow `json:"items"`
-			Total int         `json:"total"`
-		}{Items: eventRows, Total: len(eventRows)})
+		if flags.durations {
+			// pair events before filtering so that filtered out started events still provide durations
+			annotateDurations(eventRows)
+		}
+		if flags.normalizeIds {
+			normalizeOptimizerIds(eventRows)
+		}
+		if flags.annotateStages {
+			// annotate before filtering so that filtered out events still delimit the stages
+			if flags.stages = newEventStages(cmd); flags.stages != nil {
+				flags.stages.annotate(eventRows)
+				addStageColumn(cmd)
+			}
+		}
+		eventRows = flags.filterByProgress(flags.filterByAttributePresence(eventRows))
+		if flags.failOnEmpty && !flags.follow && len(eventRows) < 1 {
+			return errNoResults
+		}
+		if flags.experiments {
+			experiments := foldExperiments(eventRows)
+			// attribute maps are shared with the experiment rows, so aliasing these renames them in the output
+			attributeRows := make([]EventsRow, 0, len(experiments))
+			for _, experiment := range experiments {
+				attributeRows = append(attributeRows, EventsRow{EventAttributes: experiment.Attributes})
+				if flags.redactions.matches("optimize.optimization.optimizer_id") {
+					experiment.OptimizerId = redactedValue
+				}
+			}
+			flags.presentNumericAttributes(attributeRows)
+			flags.redactions.apply(attributeRows)
+			flags.aliasMap.apply(attributeRows)
+			printExperimentRows(cmd, experiments)
+			return nil
+		}
+		if flags.stableOrder {
+			sortStableOrder(eventRows)
+		}
+		if flags.addSequence {
+			flags.sequencer = make(eventSequencer)
+			flags.sequencer.assign(eventRows)
+			addSequenceColumn(cmd)
+		}
+		flags.checkRelativeTime(cmd)
+		sortRowsByField(eventRows, func(row EventsRow) EventsRow { return row }, flags.sortBy, flags.sortDesc)
+
+		if flags.summary {
+			printEventTypeCounts(cmd, countEventTypes(eventRows))
+			return nil
+		}
+		if flags.countOnly {
+			return flags.printEventCount(cmd, len(eventRows))
+		}
+
+		// remember events already printed so that overlapping follow windows don't print them again
+		// (before aliasing, as the event keys are derived from the original attribute names)
+		var dedup *eventDeduplicator
+		if flags.follow && !flags.noDedup {
+			dedup = newEventDeduplicator(followDedupCapacity)
+			dedup.filter(eventRows)
+		}
+
+		if flags.sqlitePath != "" {
+			// attributes are stored under their original names, only their presentation is rounded and redacted
+			flags.presentNumericAttributes(eventRows)
+			flags.redactions.apply(eventRows)
+			return writeRowsToSqlite(cmd, flags.sqlitePath, eventRows)
+		}
+		if flags.outputDir != "" {
+			// group before aliasing, which may rename the optimizer ID attribute
+			groups := groupRowsByOptimizer(eventRows)
+			flags.presentNumericAttributes(eventRows)
+			flags.redactions.apply(eventRows)
+			flags.aliasMap.apply(eventRows)
+			return writeRowsByOptimizer(cmd, flags.outputDir, groups, flags.flatten)
+		}
+
+		stopReached := flags.stop.reached(eventRows)
+		flags.presentNumericAttributes(eventRows)
+		flags.redactions.apply(eventRows)
+		if flags.legend {
+			// before aliasing, which may rename the event type attribute
+			printEventLegend(cmd, eventRows)
+		}
+		flags.aliasMap.apply(eventRows)
+		flags.annotateAges(eventRows)
+		printEventRows(cmd, eventRows, responseWarnings.drain(), flags.flatten, nil)
+		stats.finish(cmd, len(eventRows))
+		flags.snapshot.add(cmd, eventRows)
 
 		// handle follow
+		if flags.follow && stopReached {
+			flags.stop.printStopped(cmd)
+			return nil
+		}
+		if flags.follow && flags.followEach && len(optimizerIds) > 0 {
+			return followOptimizers(cmd, flags, queryVals, baseFilters, optimizerIds, dedup, eventRows)
+		}
+		if flags.follow && flags.progressPeriod > 0 {
+			return followProgressSeparately(cmd, flags, queryVals, dedup, eventRows)
+		}
 		if flags.follow && data_set != nil {
 			// setup async channels
-			interrupt := make(chan os.Signal, 1)
-			signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
 			followChan := make(chan *followEventResult, 1)
 			followChan <- &followEventResult{data_set: data_set}
+			deadline := flags.followDeadline()
+			backoff := flags.newFollowBackoff()
+			reconnects := flags.newFollowReconnects()
 
 			for {
 				select {
-				case <-interrupt:
+				case <-signals:
 					// exit requested
 					return nil
+				case <-deadline:
+					flags.printFollowStopped(cmd)
+					return nil
 				case followResult := <-followChan:
-					if followResult.err != nil {
-						return followResult.err
+					reconnectDelay, err := reconnects.check(followResult.err)
+					if err != nil {
+						return err
+					}
+					if followResult.stopReached {
+						flags.stop.printStopped(cmd)
+						return nil
 					}
 					// queue up next follow interval sleep and print
 					// run in background to allow interrupts
 					go func() {
 						// Return immediately available results (additional pages) right away.
 						// Don't start waiting until follow cursor returns a response smaller than the max page size.
-						if followResult.cursorExhausted {
-							time.Sleep(flags.followInterval)
+						if followResult.err != nil {
+							time.Sleep(reconnectDelay)
+						} else if followResult.cursorExhausted {
+							backoff.sleep()
+						} else {
+							backoff.reset()
 						}
-						followChan <- followDatasetAndPrint(cmd, followResult.data_set)
+						followChan <- followDatasetAndPrint(cmd, followResult.data_set, dedup, flags)
 					}()
 				}
 			}
@@ -322,70 +839,344 @@ func listEvents(flags *eventsCmdFlags) func(*cobra.Command, []string) error {
 
 type followEventResult struct {
 	data_set        *uql.DataSet
+	rows            []EventsRow
 	err             error
 	cursorExhausted bool
+	stopReached     bool
 }
 
-func followDatasetAndPrint(cmd *cobra.Command, data_set *uql.DataSet) *followEventResult {
+func followDatasetAndPrint(cmd *cobra.Command, data_set *uql.DataSet, dedup *eventDeduplicator, flags *eventsCmdFlags) *followEventResult {
+	result := followDataset(data_set)
+	if result.err == nil {
+		result.stopReached = printFollowedRows(cmd, result.rows, dedup, flags)
+	}
+	return result
+}
+
+// followDataset continues the follow cursor of the dataset and returns the followed dataset along with its rows.
+// Should the request fail, the dataset returned is the one given, whose cursor can be continued again
+func followDataset(data_set *uql.DataSet) *followEventResult {
 	resp, err := uql.ClientV1.ContinueQuery(data_set, "follow")
 	if err != nil {
-		return &followEventResult{err: fmt.Errorf("follow uql.ClientV1.ContinueQuery: %w", err)}
+		return &followEventResult{data_set: data_set, err: fmt.Errorf("follow uql.ClientV1.ContinueQuery: %w", err)}
 	}
-	if resp.HasErrors() {
-		log.Error("Following of events query encountered errors. Returned data may not be complete!")
-		for _, e := range resp.Errors() {
-			log.Errorf("%s: %s", e.Title, e.Detail)
-		}
+	if err := responseWarnings.checkResponse(resp, "Following", "events query"); err != nil {
+		return &followEventResult{err: err}
 	}
 	main_data_set := resp.Main()
 	if main_data_set == nil {
 		log.Error("Following of events query has nil main data. Returned data may not be complete!")
 		return &followEventResult{data_set: data_set}
 	}
-	if len(main_data_set.Data) < 1 {
-		return &followEventResult{err: fmt.Errorf("follow main dataset %v has no rows", main_data_set.Name)}
-	}
-	if len(main_data_set.Data[0]) < 1 {
-		return &followEventResult{err: fmt.Errorf("follow main dataset %v first row has no columns", main_data_set.Name)}
-	}
-	var ok bool
-	data_set, ok = main_data_set.Data[0][0].(*uql.DataSet)
-	if !ok {
-		return &followEventResult{err: fmt.Errorf("follow main dataset %v first row first column (type %T) could not be converted to *uql.DataSet", main_data_set.Name, main_data_set.Data[0][0])}
+	data_set, err = uql.FirstNestedDataSet(resp)
+	if err != nil {
+		return &followEventResult{err: fmt.Errorf("follow %w", err)}
 	}
 
 	result := &followEventResult{data_set: data_set}
-	newRows, err := extractEventsData(data_set)
+	result.rows, err = extractEventsData(data_set)
 	if err != nil {
 		result.err = fmt.Errorf("follow extractEventsData: %w", err)
 		return result
 	}
+	result.cursorExhausted = len(result.rows) < 1
+	if !result.cursorExhausted {
+		log.Infof("Follow cursor returned %v events and was not exhausted, fetching more without waiting", len(result.rows))
+	}
+	return result
+}
+
+// printFollowedRows prints the followed rows which have not been printed before and pass the output filters. It
+// reports whether the printed rows reached the --until-event stop condition
+func printFollowedRows(cmd *cobra.Command, newRows []EventsRow, dedup *eventDeduplicator, flags *eventsCmdFlags) bool {
+	newRows = dedup.filter(newRows)
+	flags.stages.annotate(newRows)
+	newRows = flags.filterByProgress(flags.filterByAttributePresence(newRows))
+	if flags.stableOrder {
+		sortStableOrder(newRows)
+	}
+	flags.sequencer.assign(newRows)
+	if flags.normalizeIds {
+		normalizeOptimizerIds(newRows)
+	}
+	stopReached := flags.stop.reached(newRows)
+	flags.presentNumericAttributes(newRows)
+	flags.redactions.apply(newRows)
+	flags.aliasMap.apply(newRows)
+	if len(newRows) > 0 {
+		if format, _ := cmd.Flags().GetString("output"); format == "yaml" {
+			// separate the follow batches so that the output remains a valid multi-document YAML stream
+			output.PrintCmdStatus(cmd, "---\n")
+		}
+		flags.annotateAges(newRows)
+		printEventRows(cmd, newRows, responseWarnings.drain(), flags.flatten, &output.Table{OmitHeaders: true})
+		flags.snapshot.add(cmd, newRows)
+	}
+	return stopReached
+}
+
+// followBackoff computes the sleep between follow requests once the cursor is exhausted, doubling it from
+// --follow-interval up to --follow-max-interval while requests return no new rows
+type followBackoff struct {
+	base    time.Duration
+	max     time.Duration
+	current time.Duration
+}
+
+func (flags *eventsFlags) newFollowBackoff() *followBackoff {
+	return &followBackoff{base: flags.followInterval, max: flags.followMaxInterval}
+}
+
+// next returns the duration to sleep before the next follow request once the cursor is exhausted, doubling the
+// previous one unless reset since
+func (backoff *followBackoff) next() time.Duration {
+	if backoff.current == 0 || backoff.max <= backoff.base {
+		backoff.current = backoff.base
+		return backoff.current
+	}
+	backoff.current = min(backoff.current*2, backoff.max)
+	return backoff.current
+}
+
+// flushOnInterrupt runs the command so that, unless following, which handles interrupts itself, the output written
+// so far is flushed before exiting when the command is interrupted, e.g., while a slow consumer reads a large export
+func flushOnInterrupt(flags *eventsCmdFlags, run func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if flags.follow {
+			return run(cmd, args)
+		}
+		return interrupt.New(nil, func() { output.FlushCmdOutput(cmd) }).Run(func() error {
+			return run(cmd, args)
+		})
+	}
+}
+
+// reset restores --follow-interval as the next sleep, once a follow request returned rows
+func (backoff *followBackoff) reset() {
+	backoff.current = 0
+}
+
+// sleep waits for the next follow request once the cursor is exhausted, see next
+func (backoff *followBackoff) sleep() {
+	delay := backoff.next()
+	log.Infof("Follow cursor exhausted, waiting %v before the next request", delay)
+	time.Sleep(delay)
+}
+
+// followDeadline returns a channel receiving once --follow-max-duration has elapsed. Without it, the returned
+// channel is nil, which blocks forever in a select
+func (flags *eventsFlags) followDeadline() <-chan time.Time {
+	if flags.followMaxDuration <= 0 {
+		return nil
+	}
+	return time.After(flags.followMaxDuration)
+}
+
+// printFollowStopped reports to stderr that following stopped because --follow-max-duration elapsed
+func (flags *eventsFlags) printFollowStopped(cmd *cobra.Command) {
+	cmd.PrintErrf("Stopped following events after %v\n", flags.followMaxDuration)
+}
+
+// parseRedactions validates the --redact flag values
+func (flags *eventsFlags) parseRedactions() error {
+	redactions, err := parseAttributeRedactions(flags.redact)
+	if err != nil {
+		return err
+	}
+	flags.redactions = redactions
+	return nil
+}
+
+// parseUnits parses the --unit conversions and renames the output columns after the chosen units
+func (flags *eventsFlags) parseUnits(cmd *cobra.Command) error {
+	units, err := parseAttributeUnits(flags.units)
+	if err != nil {
+		return err
+	}
+	if format, _ := cmd.Flags().GetString("output"); len(units) > 0 && format == prometheusOutputFormat {
+		return errors.New("--unit cannot be combined with the prometheus output format, whose metrics have fixed units")
+	}
+	flags.unitConversions = units
+	units.applyToAnnotations(cmd)
+	return nil
+}
+
+// parseAttributeRanges parses the --attr-since and --attr-until bounds
+func (flags *eventsFlags) parseAttributeRanges() error {
+	ranges, err := parseAttributeRanges(flags.attrSince, flags.attrUntil)
+	if err != nil {
+		return err
+	}
+	flags.attrRanges = ranges
+	return nil
+}
+
+// parseAliases compiles the --alias flag values and adjusts the command's output field specifications to them
+func (flags *eventsFlags) parseAliases(cmd *cobra.Command) error {
+	aliasMap, err := parseAttributeAliases(flags.aliases)
+	if err != nil {
+		return err
+	}
+	flags.aliasMap = aliasMap
+	aliasMap.applyToAnnotations(cmd)
+	return nil
+}
+
+// errNoResults is returned when --fail-on-empty is set and the query returned no rows
+var errNoResults = errors.New("no results found for given input")
+
+// noResults reports that nothing matched the given input. With --fail-on-empty, an error is returned so that
+// the command exits with a non-zero status; otherwise the message is displayed and the command succeeds
+func (flags *eventsFlags) noResults(cmd *cobra.Command, message string) error {
+	if flags.failOnEmpty {
+		return errors.New(strings.TrimSuffix(message, "\n"))
+	}
+	printNoResults(cmd, message)
+	return nil
+}
+
+// filterByAttributePresence returns the rows carrying all the --has attributes and none of the --missing attributes.
+// UQL offers no attribute existence predicate, so this is applied client-side after retrieval and combined (AND)
+// with the query filters. Note that rows are filtered after any --count limit has been applied.
+// The --attr-since and --attr-until ranges are applied along with them, as attribute times may be RFC3339 strings or
+// unix timestamps, which a single UQL predicate can't compare
+func (flags *eventsFlags) filterByAttributePresence(rows []EventsRow) []EventsRow {
+	rows = flags.attrRanges.filter(rows)
+	if len(flags.has) == 0 && len(flags.missing) == 0 {
+		return rows
+	}
+	results := make([]EventsRow, 0, len(rows))
+rowLoop:
+	for _, row := range rows {
+		for _, attr := range flags.has {
+			if _, ok := row.EventAttributes[attr]; !ok {
+				continue rowLoop
+			}
+		}
+		for _, attr := range flags.missing {
+			if _, ok := row.EventAttributes[attr]; ok {
+				continue rowLoop
+			}
+		}
+		results = append(results, row)
+	}
+	return results
+}
+
+// filtersAttributes reports whether rows are filtered client-side by their attributes, see filterByAttributePresence,
+// in which case UQL can't aggregate their counts
+func (flags *eventsFlags) filtersAttributes() bool {
+	return len(flags.has) > 0 || len(flags.missing) > 0 || len(flags.attrRanges) > 0
+}
+
+// filterByProgress applies the --only-progress, --no-progress and --exclude-progress filters to the rows. Unlike
+// --no-progress, which relies on the IsProgress tag, --exclude-progress checks the event type, so that it also
+// applies to replayed events whatever their tag
+func (flags *eventsCmdFlags) filterByProgress(rows []EventsRow) []EventsRow {
+	if !flags.filtersProgress() {
+		return rows
+	}
+	results := make([]EventsRow, 0, len(rows))
+	for _, row := range rows {
+		if (flags.onlyProgress || flags.noProgress) && row.IsProgress != flags.onlyProgress {
+			continue
+		}
+		if flags.excludeProgress && isProgressEvent(row) {
+			continue
+		}
+		results = append(results, row)
+	}
+	return results
+}
+
+// filtersProgress reports whether events are filtered client-side by whether they are progress events
+func (flags *eventsCmdFlags) filtersProgress() bool {
+	return flags.onlyProgress || flags.noProgress || flags.excludeProgress
+}
 
-	newRowsCount := len(newRows)
-	if newRowsCount > 0 {
+// aggregatesCounts reports whether the event counts of --summary, --list-types and --count-only can be aggregated
+// by UQL, rather than counted over the retrieved events. Aggregation can't honor a --count limit, a resumed cursor,
+// rows read from a file or the client-side attribute presence and progress filters
+func (flags *eventsCmdFlags) aggregatesCounts() bool {
+	return flags.count == -1 && !flags.resume && flags.fromFile == "" && !flags.filtersAttributes() && !flags.filtersProgress()
+}
+
+// flattenedAttributePrefix is prepended to event attribute names promoted to top-level keys by --flatten
+// so that they cannot collide with Timestamp
+const flattenedAttributePrefix = "attributes."
+
+// printEventRows prints the event rows with the query warnings, promoting the event attributes to top-level keys
+// when flatten is requested and the output format is JSON
+func printEventRows(cmd *cobra.Command, rows []EventsRow, warnings []queryWarning, flatten bool, table *output.Table) {
+	if format, _ := cmd.Flags().GetString("output"); flatten && (format == "json" || format == "json-compact") {
+		flatRows := make([]map[string]any, 0, len(rows))
+		for _, row := range rows {
+			flatRows = append(flatRows, flattenEventRow(row))
+		}
 		output.PrintCmdOutputCustom(cmd, struct {
-			Items []EventsRow `json:"items"`
-			Total int         `json:"total"`
-		}{Items: newRows, Total: newRowsCount}, &output.Table{OmitHeaders: true})
-	} else {
-		result.cursorExhausted = true
+			Items    []map[string]any `json:"items"`
+			Total    int              `json:"total"`
+			Warnings []queryWarning   `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+		}{Items: flatRows, Total: len(flatRows), Warnings: warnings}, table)
+		return
 	}
-	return result
+	output.PrintCmdOutputCustom(cmd, struct {
+		Items    []EventsRow    `json:"items"`
+		Total    int            `json:"total"`
+		Warnings []queryWarning `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+	}{Items: rows, Total: len(rows), Warnings: warnings}, table)
+}
+
+// flattenEventRow promotes each event attribute of the row to a top-level key prefixed with flattenedAttributePrefix
+func flattenEventRow(row EventsRow) map[string]any {
+	flatRow := make(map[string]any, len(row.EventAttributes)+1)
+	for key, value := range row.EventAttributes {
+		flatRow[flattenedAttributePrefix+key] = value
+	}
+	if row.Seq != 0 {
+		flatRow["Seq"] = row.Seq
+	}
+	flatRow["Timestamp"] = row.Timestamp
+	flatRow["IsProgress"] = row.IsProgress
+	if row.Duration != "" {
+		flatRow["Duration"] = row.Duration
+	}
+	if row.OptUUID != "" {
+		flatRow["OptNamespace"], flatRow["OptName"], flatRow["OptUUID"] = row.OptNamespace, row.OptName, row.OptUUID
+	}
+	return flatRow
 }
 
 type recommendationsCmdFlags struct {
 	eventsFlags
 	includeInvalidated bool
+	onlyBlocked        bool
+	onlyUnblocked      bool
+	exportPatch        bool
+	savingsReport      bool
+	compareOptimizer   string
+	attemptLimit       int
+	minCpu             float64
+	maxCpu             float64
+	minMemory          float64
+	maxMemory          float64
+	keepUnparseable    bool
 }
 
 func NewCmdRecommendations() *cobra.Command {
-	var flags recommendationsCmdFlags
+	return newCmdRecommendations(&recommendationsCmdFlags{})
+}
+
+// newCmdRecommendations returns the recommendations command parsing its flags into flags, so that tests can inspect them
+func newCmdRecommendations(flags *recommendationsCmdFlags) *cobra.Command {
 	command := &cobra.Command{
 		Use:   "recommendations",
 		Short: "Retrieve resulting recommendations for a given optimization/workload",
 		Example: `  fsoc optimize recommendations --optimizer-id namespace-name-00000000-0000-0000-0000-000000000000
-  fsoc optimize recommendations --optimizer-id namespace-name-00000000-0000-0000-0000-000000000000 --include-invalidated --count 5`,
-		RunE:             listRecommendations(&flags),
+  fsoc optimize recommendations --optimizer-id namespace-name-00000000-0000-0000-0000-000000000000 --include-invalidated --count 5
+  fsoc optimize recommendations --optimizer-id namespace-name-00000000-0000-0000-0000-000000000000 --export-patch > patch.yaml
+  fsoc optimize recommendations --namespace some-namespace --output prometheus | curl --data-binary @- http://pushgateway:9091/metrics/job/fsoc`,
+		RunE:             listRecommendations(flags),
 		TraverseChildren: true,
 		Annotations: map[string]string{
 			output.TableFieldsAnnotation:  "OptimizerId: .EventAttributes[\"optimize.optimization.optimizer_id\"], State: .EventAttributes[\"optimize.recommendation.state\"], CPUcores: .EventAttributes[\"optimize.recommendation.settings.cpu\"], MemoryGiB: .EventAttributes[\"optimize.recommendation.settings.memory\"], Blockers: .BlockersPresent, Timestamp: .Timestamp",
@@ -400,189 +1191,265 @@ func NewCmdRecommendations() *cobra.Command {
 	command.MarkFlagsMutuallyExclusive("optimizer-id", "cluster-id")
 	command.MarkFlagsMutuallyExclusive("optimizer-id", "namespace")
 	command.MarkFlagsMutuallyExclusive("optimizer-id", "workload-name")
+	command.Flags().StringVarP(&flags.entityFilter, "entity-filter", "", "", "Retrieve recommendations of the optimizations matching a UQL predicate on the optimization entities, combined with the other filters by AND, e.g., \"attributes(k8s.cluster.name) = 'prod'\"")
+	command.MarkFlagsMutuallyExclusive("entity-filter", "optimizer-id")
+	command.Flags().BoolVarP(&flags.interactive, "interactive", "", false, "Prompt to select among the optimizers matching --namespace or --workload-name when more than one matches")
+	command.MarkFlagsMutuallyExclusive("interactive", "optimizer-id")
+	command.Flags().StringVarP(&flags.principal, "principal", "", "", "Retrieve recommendations triggered by the given principal ID, combined with the other filters by AND")
+	command.Flags().StringVarP(&flags.principalAttr, "principal-attr", "", defaultPrincipalAttribute, "Attribute holding the principal ID matched by --principal, should the recommendations carry it under another name")
+	command.Flags().StringVarP(&flags.optimizerIdFile, "optimizer-id-file", "", "", "Retrieve recommendations for the optimizers listed in the given file, one ID per line, skipping blank lines and lines starting with #. Combines with --optimizer-id")
+	for _, flag := range []string{"namespace", "workload-name", "entity-filter", "interactive"} {
+		command.MarkFlagsMutuallyExclusive("optimizer-id-file", flag)
+	}
 
 	command.Flags().BoolVarP(&flags.includeInvalidated, "include-invalidated", "", false, "Include recommendations that have not been verified")
-
-	command.Flags().StringVarP(&flags.since, "since", "s", "-52w", "Retrieve recommendations contained in the time interval starting at a relative or exact time.")
-	command.Flags().StringVarP(&flags.until, "until", "u", "", "Retrieve recommendations contained in the time interval ending at a relative or exact time. (default: now)")
-
-	command.Flags().IntVarP(&flags.count, "count", "", 1, "Limit the number of recommendations retrieved to the specified count")
+	command.Flags().BoolVarP(&flags.onlyBlocked, "only-blocked", "", false, "Only output recommendations which have blockers present")
+	command.Flags().BoolVarP(&flags.onlyUnblocked, "only-unblocked", "", false, "Only output recommendations which have no blockers present")
+	command.MarkFlagsMutuallyExclusive("only-blocked", "only-unblocked")
+
+	command.Flags().Float64VarP(&flags.minCpu, "min-cpu", "", 0, "Only output recommendations of at least the given CPU cores")
+	command.Flags().Float64VarP(&flags.maxCpu, "max-cpu", "", 0, "Only output recommendations of at most the given CPU cores")
+	command.Flags().Float64VarP(&flags.minMemory, "min-memory", "", 0, "Only output recommendations of at least the given memory GiB")
+	command.Flags().Float64VarP(&flags.maxMemory, "max-memory", "", 0, "Only output recommendations of at most the given memory GiB")
+	command.Flags().BoolVarP(&flags.keepUnparseable, "keep-unparseable", "", false, "Keep recommendations whose CPU or memory setting is missing or not numeric when filtering by --min/--max thresholds")
+
+	command.Flags().StringSliceVarP(&flags.has, "has", "", nil, "Only output recommendations carrying the given attribute. May be repeated, evaluated client-side after retrieval")
+	command.Flags().StringSliceVarP(&flags.missing, "missing", "", nil, "Only output recommendations not carrying the given attribute. May be repeated, evaluated client-side after retrieval")
+	command.Flags().StringSliceVarP(&flags.attrSince, "attr-since", "", nil, "Only output recommendations whose time attribute is at or after the given time, in the form attribute=time with an RFC3339 time or unix timestamp. Unlike --since, which bounds when recommendations were emitted, this bounds a time carried by the recommendations. May be repeated, evaluated client-side after retrieval")
+	command.Flags().StringSliceVarP(&flags.attrUntil, "attr-until", "", nil, "Only output recommendations whose time attribute is before the given time, in the form attribute=time, see --attr-since. May be repeated, evaluated client-side after retrieval")
+
+	command.Flags().StringVarP(&flags.sortBy, "sort-by", "", "", "Sort the output recommendations by a comma separated list of optimizer-id, state, timestamp, cpu, memory or attribute names, e.g., optimizer-id,timestamp, placing recommendations missing an attribute last")
+	command.Flags().BoolVarP(&flags.sortDesc, "sort-desc", "", false, "Sort in descending order when used with --sort-by")
+
+	command.Flags().BoolVarP(&flags.normalizeIds, "normalize-optimizer-id", "", false, "Split the optimizer ID of each recommendation into its OptNamespace, OptName and OptUUID components, shown as columns and fields")
+	command.Flags().BoolVarP(&flags.exportPatch, "export-patch", "", false, "Output kubernetes strategic merge patches with the recommended resource requests and limits. Patches are only generated, never applied")
+	command.Flags().BoolVarP(&flags.savingsReport, "savings-report", "", false, "Output the CPU and memory savings of the latest verified recommendation of each workload, current minus recommended settings, and their totals. Recommendations lacking current settings are excluded from the sums. Unless --count is given, all recommendations in the time interval are considered")
+	command.MarkFlagsMutuallyExclusive("savings-report", "export-patch")
+	command.Flags().StringVarP(&flags.compareOptimizer, "compare-optimizer", "", "", "Compare the latest recommendation of the --optimizer-id optimizer with the latest recommendation of the given optimizer, field by field, marking the differing CPU, memory, state and blockers. Unless --count is given, all recommendations in the time interval are considered")
+	for _, flag := range []string{"savings-report", "export-patch", "optimizer-id-file"} {
+		command.MarkFlagsMutuallyExclusive("compare-optimizer", flag)
+	}
+	command.Flags().IntVarP(&flags.attemptLimit, "attempt-limit", "", 3, "Number of attempts of the optimization_started query supplying the blockers, after which the recommendations are output without blockers and a warning")
+
+	command.Flags().StringVarP(&flags.since, "since", "s", recommendationsLookbackSince, "Retrieve recommendations contained in the time interval starting at a relative or exact time, a calendar anchor such as @week or a unix timestamp in seconds or milliseconds.")
+	command.Flags().StringVarP(&flags.until, "until", "u", "", "Retrieve recommendations contained in the time interval ending at a relative or exact time, a calendar anchor such as @day or a unix timestamp in seconds or milliseconds. (default: now)")
+
+	command.Flags().BoolVarP(&flags.failOnEmpty, "fail-on-empty", "", false, "Exit with an error when no optimizations match the filters or no recommendations are found")
+	command.Flags().IntVarP(&flags.precision, "precision", "", -1, "Round numeric attributes such as the recommended settings to the given number of decimal places")
+	command.Flags().StringSliceVarP(&flags.units, "unit", "", nil, "Convert a recommended setting to the given unit in the output, in the form resource=unit, e.g., cpu=millicores or memory=MiB. Units for cpu: cores, millicores; for memory: bytes, KiB, MiB, GiB, TiB. May be repeated")
+	command.Flags().StringSliceVarP(&flags.aliases, "alias", "", nil, "Rename an attribute in the output, in the form attribute=alias. May be repeated")
+	command.Flags().StringSliceVarP(&flags.redact, "redact", "", nil, "Mask the value of an attribute of the recommendations and of their blockers in the output with ***. Accepts globs such as optimize.principal.* and may be repeated. Applies to presentation only, the query is unaffected")
+	command.Flags().StringVarP(&flags.window, "window", "", "", fmt.Sprintf("Retrieve recommendations contained in a named local time window instead of --since/--until. One of: %v", strings.Join(windowPresets, ", ")))
+	command.Flags().BoolVarP(&flags.explain, "explain", "", false, "Describe what the command would do with the given flags instead of running it")
+	command.Flags().StringVarP(&flags.filterProfile, "filter-profile", "", "", fmt.Sprintf("Apply the cluster, namespace, workload and time flags of the named profile in %v, unless given on the command line", filterProfilesFile))
+	command.MarkFlagsMutuallyExclusive("window", "since")
+	command.MarkFlagsMutuallyExclusive("window", "until")
+	command.Flags().StringVarP(&flags.timeRange, "range", "", "", "Retrieve recommendations contained in the time interval of the form since..until instead of --since/--until, e.g., -7d..-1d; an empty until means now")
+	command.Flags().StringVarP(&flags.timezone, "timezone", "", "", "IANA time zone, e.g., Europe/Paris, in which --window presets and the calendar anchors @day, @week and @month of --since, --until and --range are resolved to midnight, weeks starting on Monday. (default: local time zone)")
+	for _, flag := range []string{"since", "until", "window"} {
+		command.MarkFlagsMutuallyExclusive("range", flag)
+	}
+	command.Flags().IntVarP(&flags.count, "count", "", 1, fmt.Sprintf("Limit the number of recommendations retrieved to the specified count. Counts above %v are retrieved across multiple pages", maxLimitsCount))
 
 	command.Flags().StringVarP(&flags.solutionName, "solution-name", "", "optimize", "Intended for developer usage, overrides the name of the solution defining the FMM types for reading")
 	if err := command.LocalFlags().MarkHidden("solution-name"); err != nil {
 		log.Warnf("Failed to set recommendations solution-name flag hidden: %v", err)
 	}
+	command.Flags().BoolVarP(&flags.debugTiming, "debug-timing", "", false, "Print a summary of UQL query timings to stderr on completion")
+	addStrictFlag(command)
+	command.Flags().BoolVarP(&flags.stats, "stats", "", false, "Print the number of rows and pages retrieved and the elapsed time to stderr after table output")
 
 	return command
 }
 
-type recommendationsTemplateValues struct {
+type recommendationsQueryValues struct {
 	Since              string
 	Until              string
 	IncludeInvalidated bool
-	Filter             string
-	Limits             string
+	Filters            []string
+	Limits             int  // per page, 0 for the default page size
+	Desc               bool // newest recommendations first
 	SolutionName       string
 }
 
-var recommendationsTemplate = template.Must(template.New("").Parse(`
-{{ with .Since }}SINCE {{ . }}
-{{ end -}}
-{{ with .Until }}UNTIL {{ . }}
-{{ end -}}
-FETCH events(
-		{{- if .IncludeInvalidated }}
-		{{ .SolutionName }}:recommendation_identified,
-		{{ .SolutionName }}:recommendation_invalidated,
-		{{- end }}
-		{{ .SolutionName }}:recommendation_verified
-	)
-	{{ with .Filter }}[{{ . }}]
-	{{ end -}}
-	{attributes, timestamp}
-{{ with .Limits }}LIMITS events.count({{ . }})
-{{ end -}}
-ORDER events.asc()
-`))
-
-var optimizationStartedTemplate = template.Must(template.New("").Parse(`
-{{ with .Since }}SINCE {{ . }}
-{{ end -}}
-{{ with .Until }}UNTIL {{ . }}
-{{ end -}}
-FETCH events(
-		{{ .SolutionName }}:optimization_started
-	)
-	{{ with .Filter }}[{{ . }}]
-	{{ end -}}
-	{attributes, timestamp}
-ORDER events.asc()
-`))
+func recommendationsQuery(queryVals recommendationsQueryValues) *uql.Query {
+	events := make([]string, 0, 3)
+	if queryVals.IncludeInvalidated {
+		events = append(events,
+			fmt.Sprintf("%v:recommendation_identified", queryVals.SolutionName),
+			fmt.Sprintf("%v:recommendation_invalidated", queryVals.SolutionName),
+		)
+	}
+	events = append(events, fmt.Sprintf("%v:recommendation_verified", queryVals.SolutionName))
+
+	builder := uql.NewBuilder().
+		Since(queryVals.Since).
+		Until(queryVals.Until).
+		Fetch("events", events...).
+		Where(queryVals.Filters...).
+		Fields("attributes", "timestamp")
+	if queryVals.Limits > 0 {
+		builder.Limit("events", queryVals.Limits)
+	}
+	if queryVals.Desc {
+		return builder.OrderDesc("events").Build()
+	}
+	return builder.OrderAsc("events").Build()
+}
+
+func optimizationStartedQuery(queryVals recommendationsQueryValues) *uql.Query {
+	return uql.NewBuilder().
+		Since(queryVals.Since).
+		Until(queryVals.Until).
+		Fetch("events", fmt.Sprintf("%v:optimization_started", queryVals.SolutionName)).
+		Where(queryVals.Filters...).
+		Fields("attributes", "timestamp").
+		OrderAsc("events").
+		Build()
+}
+
+// buildRecommendationsQueryValues returns the values of the optimization_started query supplying the blockers and
+// of the recommendations query for the flags. Both are constrained to the --compare-optimizer pair if given, or else
+// to the optimizers of the filters, see filteredOptimizerIds
+func buildRecommendationsQueryValues(flags *recommendationsCmdFlags) (queryVals recommendationsQueryValues, recommendationVals recommendationsQueryValues, err error) {
+	optimizerIds, err := flags.filteredOptimizerIds()
+	if err != nil {
+		return queryVals, recommendationVals, err
+	}
+	queryVals = recommendationsQueryValues{
+		Since:              flags.since,
+		Until:              flags.until,
+		IncludeInvalidated: flags.includeInvalidated,
+		SolutionName:       flags.solutionName,
+	}
+
+	filterList := make([]string, 0, 2)
+	if flags.clusterId != "" {
+		filterList = append(filterList, uql.AttributeEquals("k8s.cluster.id", flags.clusterId))
+	}
+	if flags.compareOptimizer != "" {
+		filterList = append(filterList, uql.AttributeIn("optimize.optimization.optimizer_id", []string{flags.optimizerId, flags.compareOptimizer}))
+	} else if optimizerIds != nil {
+		filterList = append(filterList, uql.AttributeIn("optimize.optimization.optimizer_id", optimizerIds))
+	} else if flags.optimizerId != "" {
+		filterList = append(filterList, uql.AttributeEquals("optimize.optimization.optimizer_id", flags.optimizerId))
+	}
+	queryVals.Filters = filterList
+
+	if flags.count != -1 {
+		if flags.count < 1 {
+			return queryVals, recommendationVals, errors.New("counts must be positive")
+		}
+		if flags.count <= maxLimitsCount {
+			queryVals.Limits = flags.count
+		}
+	}
+
+	recommendationVals = queryVals
+	// the count is of the most recent recommendations, which are retrieved first so that pagination can stop at it
+	recommendationVals.Desc = flags.count != -1
+	if flags.principal != "" {
+		// only the recommendations are constrained, the optimization_started events supplying their blockers
+		// may have been triggered by another principal
+		recommendationVals.Filters = append(append([]string{}, queryVals.Filters...), uql.AttributeEquals(flags.principalAttr, flags.principal))
+	}
+	return queryVals, recommendationVals, nil
+}
+
+// buildRecommendationsQuery returns the UQL of the recommendations query for the flags, see
+// buildRecommendationsQueryValues
+func buildRecommendationsQuery(flags *recommendationsCmdFlags) (string, error) {
+	_, recommendationVals, err := buildRecommendationsQueryValues(flags)
+	if err != nil {
+		return "", err
+	}
+	return recommendationsQuery(recommendationVals).Str, nil
+}
 
 func listRecommendations(flags *recommendationsCmdFlags) func(*cobra.Command, []string) error {
 	return func(cmd *cobra.Command, args []string) error {
-		// setup query
-		tempVals := recommendationsTemplateValues{
-			Since:              flags.since,
-			Until:              flags.until,
-			IncludeInvalidated: flags.includeInvalidated,
-			SolutionName:       flags.solutionName,
+		flags.retries = newRetryBudget(cmd)
+		responseWarnings.setStrict(cmd)
+		if err := flags.applyFilterProfile(cmd); err != nil {
+			return err
 		}
-
-		filterList := make([]string, 0, 2)
-		if flags.clusterId != "" {
-			filterList = append(filterList, fmt.Sprintf("attributes(k8s.cluster.id) = %q", flags.clusterId))
+		if err := flags.checkEntityFilter(cmd); err != nil {
+			return err
 		}
-		if flags.optimizerId != "" {
-			filterList = append(filterList, fmt.Sprintf("attributes(optimize.optimization.optimizer_id) = %q", flags.optimizerId))
-		} else if flags.namespace != "" || flags.workloadName != "" {
-			optimizerIds, err := listOptimizations(&flags.eventsFlags)
-			if err != nil {
-				return fmt.Errorf("listOptimizations: %w", err)
-			}
-			if len(optimizerIds) < 1 {
-				output.PrintCmdStatus(cmd, "No optimization entities found matching the given criteria\n")
-				return nil
-			}
-			optIdStr := strings.Join(optimizerIds, "\", \"")
-			filterList = append(filterList, fmt.Sprintf("attributes(optimize.optimization.optimizer_id) IN [\"%v\"]", optIdStr))
+		if err := flags.checkPrincipal(cmd); err != nil {
+			return err
 		}
-		tempVals.Filter = strings.Join(filterList, " && ")
-
-		if flags.count != -1 {
-			if flags.count > 1000 {
-				return errors.New("counts higher than 1000 are not supported")
-			}
-			tempVals.Limits = strconv.Itoa(flags.count)
+		if err := flags.checkInteractive(cmd); err != nil {
+			return err
 		}
-
-		var buff bytes.Buffer
-		if err := recommendationsTemplate.Execute(&buff, tempVals); err != nil {
-			return fmt.Errorf("recommendationsTemplate.Execute: %w", err)
+		if err := flags.applyWindow(); err != nil {
+			return err
 		}
-		query := buff.String()
-
-		// execute query, process results
-		resp, err := uql.ClientV1.ExecuteQuery(&uql.Query{Str: query})
-		if err != nil {
-			return fmt.Errorf("uql.ClientV1.ExecuteQuery: %w", err)
+		if flags.normalizeIds {
+			// before aliasing, which adjusts the output columns to the renamed attributes
+			addOptimizerIdColumns(cmd)
 		}
-		if resp.HasErrors() {
-			log.Error("Execution of recommendations query encountered errors. Returned data may not be complete!")
-			for _, e := range resp.Errors() {
-				log.Errorf("%s: %s", e.Title, e.Detail)
-			}
+		if err := flags.parseAliases(cmd); err != nil {
+			return err
 		}
-
-		main_data_set := resp.Main()
-		if main_data_set == nil || len(main_data_set.Data) < 1 {
-			output.PrintCmdStatus(cmd, "No recommendation results found for given input\n")
+		if err := flags.parseRedactions(); err != nil {
+			return err
+		}
+		if err := flags.parseUnits(cmd); err != nil {
+			return err
+		}
+		if err := flags.parseAttributeRanges(); err != nil {
+			return err
+		}
+		if err := flags.checkCompareOptimizer(); err != nil {
+			return err
+		}
+		if flags.attemptLimit < 1 {
+			return errors.New("--attempt-limit must be positive")
+		}
+		if flags.explain {
+			flags.explainRecommendations(cmd)
 			return nil
 		}
-		if len(main_data_set.Data[0]) < 1 {
-			return fmt.Errorf("main dataset %v first row has no columns", main_data_set.Name)
+		if flags.debugTiming {
+			defer startDebugTiming(cmd)()
 		}
+		stats := startStats(&flags.eventsFlags)
+		defer stats.stop()
 
-		data_set, ok := main_data_set.Data[0][0].(*uql.DataSet)
-		if !ok {
-			return fmt.Errorf("main dataset %v first row first column (type %T) could not be converted to *uql.DataSet", main_data_set.Name, main_data_set.Data[0][0])
+		if (flags.savingsReport || flags.compareOptimizer != "") && !cmd.Flags().Changed("count") {
+			// the savings span all workloads and the comparison both optimizers, not only the latest recommendation
+			flags.count = -1
+		}
+		// setup query
+		flags.resolver = flags.commandResolver(cmd)
+		queryVals, recommendationVals, err := buildRecommendationsQueryValues(flags)
+		if errors.Is(err, errNoOptimizations) {
+			return flags.noResults(cmd, "No optimization entities found matching the given criteria\n")
 		}
-		recommendationRows, err := extractEventsData(data_set)
 		if err != nil {
-			return fmt.Errorf("extractEventsData: %w", err)
+			return err
 		}
-
-		// handle pagination
-		next_ok := false
-		if data_set != nil {
-			_, next_ok = data_set.Links["next"]
+		recommendationRows, found, err := fetchRecommendationRows(recommendationVals, flags.count, flags.retries)
+		if err != nil {
+			return err
 		}
-		if flags.count != -1 {
-			// skip pagination if limits provided. Otherwise, we return the full result list (chunked into count per response)
-			// instead of constraining to count
-			next_ok = false
+		if !found {
+			return flags.noResults(cmd, "No recommendation results found for given input\n")
 		}
-		for page := 2; next_ok; page++ {
-			resp, err = uql.ClientV1.ContinueQuery(data_set, "next")
-			if err != nil {
-				return fmt.Errorf("page %v uql.ClientV1.ContinueQuery: %w", page, err)
-			}
-			if resp.HasErrors() {
-				log.Errorf("Continuation of recommendations query (page %v) encountered errors. Returned data may not be complete!", page)
-				for _, e := range resp.Errors() {
-					log.Errorf("%s: %s", e.Title, e.Detail)
-				}
-			}
-			main_data_set := resp.Main()
-			if main_data_set == nil {
-				log.Errorf("Continuation of recommendations query (page %v) has nil main data. Returned data may not be complete!", page)
-				break
-			}
-			if len(main_data_set.Data) < 1 {
-				return fmt.Errorf("page %v main dataset %v has no rows", page, main_data_set.Name)
-			}
-			if len(main_data_set.Data[0]) < 1 {
-				return fmt.Errorf("page %v main dataset %v first row has no columns", page, main_data_set.Name)
-			}
-			data_set, ok = main_data_set.Data[0][0].(*uql.DataSet)
-			if !ok {
-				return fmt.Errorf("page %v main dataset %v first row first column (type %T) could not be converted to *uql.DataSet", page, main_data_set.Name, main_data_set.Data[0][0])
-			}
 
-			newRows, err := extractEventsData(data_set)
-			if err != nil {
-				return fmt.Errorf("page %v extractEventsData: %w", page, err)
-			}
-			recommendationRows = append(recommendationRows, newRows...)
-			_, next_ok = data_set.Links["next"]
+		recommendationRows = flags.filterByAttributePresence(recommendationRows)
+		if flags.normalizeIds {
+			normalizeOptimizerIds(recommendationRows)
 		}
 
 		recommendationRowsWithBlockers := make([]recommendationRow, 0, len(recommendationRows))
 
-		// extract blocker rows
-		blockerRows, err := getOptimizationBlockerData(tempVals)
+		// extract blocker rows, the optimization_started query sharing the optimizer filters, and thus the resolved
+		// optimizer IDs, of the recommendations query
+		blockerRows, blockersFound, err := getOptimizationBlockerDataAttempts(queryVals, flags.retries, flags.attemptLimit)
 		if err != nil {
-			return fmt.Errorf("failed to retrieve optimization_started blocker data: %v", err)
+			return err
 		}
 
 		// iterate through recommendations rows and append blocker data from optimization_started events, linking on optimizer ID + num
@@ -599,7 +1466,9 @@ func listRecommendations(flags *recommendationsCmdFlags) func(*cobra.Command, []
 
 			// merge recommendation and blocker data
 			if startedRow, ok := blockerRows[uniqueKey]; !ok {
-				log.Warnf("No optimization_started event found for recommendation with optimizer_id: %v and num: %v", optimizerId, optimizationNum)
+				if blockersFound {
+					log.Warnf("No optimization_started event found for recommendation with optimizer_id: %v and num: %v", optimizerId, optimizationNum)
+				}
 			} else {
 				for attr, val := range startedRow.(map[string]any) {
 					recommendationWithBlockers.BlockersAttributes[attr] = val
@@ -621,70 +1490,223 @@ func listRecommendations(flags *recommendationsCmdFlags) func(*cobra.Command, []
 				recommendationWithBlockers.BlockersPresent = "true"
 			}
 
+			if flags.onlyBlocked && recommendationWithBlockers.BlockersPresent != "true" {
+				continue
+			}
+			if flags.onlyUnblocked && recommendationWithBlockers.BlockersPresent == "true" {
+				continue
+			}
+			if !flags.withinThresholds(cmd, recommendationWithBlockers.EventsRow) {
+				continue
+			}
 			recommendationRowsWithBlockers = append(recommendationRowsWithBlockers, recommendationWithBlockers)
 		}
 
+		if flags.failOnEmpty && len(recommendationRowsWithBlockers) < 1 {
+			return errNoResults
+		}
+		sortRowsByFields(recommendationRowsWithBlockers, func(row recommendationRow) EventsRow { return row.EventsRow }, flags.sortBy, recommendationSortKeys, flags.sortDesc)
+
+		if flags.exportPatch {
+			return printRecommendationPatches(cmd, buildRecommendationPatches(recommendationRowsWithBlockers, flags.solutionName))
+		}
+		if flags.savingsReport {
+			flags.printSavingsReport(cmd, buildSavingsReport(recommendationRowsWithBlockers))
+			return nil
+		}
+		if flags.compareOptimizer != "" {
+			// attribute maps are shared with the recommendation rows, so the settings are compared as presented
+			eventRows := make([]EventsRow, 0, len(recommendationRowsWithBlockers))
+			for _, row := range recommendationRowsWithBlockers {
+				eventRows = append(eventRows, row.EventsRow)
+			}
+			flags.presentNumericAttributes(eventRows)
+			diff, err := flags.buildRecommendationDiff(recommendationRowsWithBlockers)
+			if err != nil {
+				return err
+			}
+			printRecommendationDiff(cmd, diff)
+			return nil
+		}
+
+		// attribute maps are shared with the recommendation rows, so aliasing these renames them in the output
+		eventRows := make([]EventsRow, 0, len(recommendationRowsWithBlockers))
+		for _, row := range recommendationRowsWithBlockers {
+			eventRows = append(eventRows, row.EventsRow)
+		}
+		flags.presentNumericAttributes(eventRows)
+		flags.redactions.apply(eventRows)
+		for _, row := range recommendationRowsWithBlockers {
+			// the blocker attributes of the optimization_started events may carry the same principals
+			flags.redactions.applyToAttributes(row.BlockersAttributes)
+		}
+		if format, _ := cmd.Flags().GetString("output"); format == prometheusOutputFormat {
+			// before aliasing, as the metric names and labels are derived from the original attribute names
+			printRecommendationMetrics(cmd, eventRows)
+			stats.finish(cmd, len(eventRows))
+			return nil
+		}
+		flags.aliasMap.apply(eventRows)
+
 		output.PrintCmdOutput(cmd, struct {
-			Items []recommendationRow `json:"items"`
-			Total int                 `json:"total"`
-		}{Items: recommendationRowsWithBlockers, Total: len(recommendationRowsWithBlockers)})
+			Items    []recommendationRow `json:"items"`
+			Total    int                 `json:"total"`
+			Warnings []queryWarning      `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+		}{Items: recommendationRowsWithBlockers, Total: len(recommendationRowsWithBlockers), Warnings: responseWarnings.drain()})
+		stats.finish(cmd, len(recommendationRowsWithBlockers))
 
 		return nil
 	}
 }
 
-func getOptimizationBlockerData(tempVals recommendationsTemplateValues) (map[string]any, error) {
+// fetchRecommendationRows executes the recommendations query and retrieves its pages until count rows are accumulated
+// (or all pages, if count is -1). When count is set, the query must order the recommendations newest first, see
+// recommendationsQueryValues.Desc, for the most recent count recommendations to be returned, newest first.
+// found is false if the query returned no data
+func fetchRecommendationRows(queryVals recommendationsQueryValues, count int, retries *retryBudget) ([]EventsRow, bool, error) {
+	// execute query, process results
+	resp, err := uql.ClientV1.ExecuteQuery(recommendationsQuery(queryVals))
+	if err != nil {
+		return nil, false, fmt.Errorf("uql.ClientV1.ExecuteQuery: %w", err)
+	}
+	if err := responseWarnings.checkResponse(resp, "Execution", "recommendations query"); err != nil {
+		return nil, false, err
+	}
+
+	main_data_set := resp.Main()
+	if main_data_set == nil || len(main_data_set.Data) < 1 {
+		return nil, false, nil
+	}
+
+	// handle pagination
+	recommendationRows := []EventsRow{}
+	pages := uql.Pages{Nested: true, Continue: retries.continueQuery, Description: "recommendations query", OnErrors: responseWarnings.onPageErrors("recommendations query")}
+	_, err = pages.Iterate(resp, func(page int, dataSet *uql.DataSet) (bool, error) {
+		newRows, err := extractEventsData(dataSet)
+		if err != nil {
+			return false, fmt.Errorf("page %v extractEventsData: %w", page, err)
+		}
+		recommendationRows = append(recommendationRows, newRows...)
+		// limits are applied per response page, so only continue paginating until the requested count
+		// has been accumulated across pages
+		return count == -1 || len(recommendationRows) < count, nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	// trim to the requested count, keeping the most recent recommendations
+	if count != -1 {
+		sort.SliceStable(recommendationRows, func(i, j int) bool {
+			return recommendationRows[i].Timestamp.After(recommendationRows[j].Timestamp)
+		})
+		if len(recommendationRows) > count {
+			recommendationRows = recommendationRows[:count]
+		}
+	}
+
+	return recommendationRows, true, nil
+}
+
+// recommendationsLookbackSince is the default start of the time interval searched for recommendations
+const recommendationsLookbackSince = "-52w"
 
-	var buff bytes.Buffer
-	if err := optimizationStartedTemplate.Execute(&buff, tempVals); err != nil {
-		return nil, fmt.Errorf("optimizationStartedTemplate.Execute: %w", err)
+// latestRecommendationSince returns the timestamp of the newest verified recommendation for the optimizer ID,
+// formatted for use as since. found is false if there is no such recommendation. Only the newest recommendation is
+// queried, rather than paging through those of the whole lookback interval
+func latestRecommendationSince(flags *eventsFlags) (string, bool, error) {
+	rows, found, err := fetchRecommendationRows(latestRecommendationQueryValues(flags), 1, flags.retries)
+	if err != nil || !found || len(rows) < 1 {
+		return "", false, err
 	}
-	query := buff.String()
+	return rows[0].Timestamp.Format(time.RFC3339Nano), true, nil
+}
 
+func latestRecommendationQueryValues(flags *eventsFlags) recommendationsQueryValues {
+	return recommendationsQueryValues{
+		Since:        recommendationsLookbackSince,
+		Filters:      []string{uql.AttributeEquals("optimize.optimization.optimizer_id", flags.optimizerId)},
+		Limits:       1,
+		Desc:         true,
+		SolutionName: flags.solutionName,
+	}
+}
+
+// getOptimizationBlockerDataAttempts attempts getOptimizationBlockerData up to attempts times, doubling the delay
+// between attempts. Once all attempts failed, false is returned and the last error is recorded as a warning, so that the
+// recommendations are returned without their blockers rather than not at all. With --strict, the error is returned
+// instead, as the recommendations would be incomplete
+func getOptimizationBlockerDataAttempts(queryVals recommendationsQueryValues, retries *retryBudget, attempts int) (map[string]any, bool, error) {
+	delay := initialRetryDelay
+	for attempt := 1; ; attempt++ {
+		results, err := getOptimizationBlockerData(queryVals, retries)
+		if err == nil {
+			return results, true, nil
+		}
+		var partialErr *partialDataError
+		if errors.As(err, &partialErr) {
+			// with --strict, errors reported within the responses fail the command rather than being retried
+			return nil, false, err
+		}
+		if attempt >= attempts {
+			if responseWarnings.strict {
+				return nil, false, fmt.Errorf("failed to retrieve optimization_started blocker data after %v attempts (failing due to --strict): %w", attempts, err)
+			}
+			log.Warnf("Failed to retrieve optimization_started blocker data after %v attempts, blockers are omitted: %v", attempts, err)
+			responseWarnings.add("optimization_started query", 0, []*uql.Error{{Title: "Blockers omitted", Detail: err.Error()}})
+			return map[string]any{}, false, nil
+		}
+		log.Warnf("Failed to retrieve optimization_started blocker data, retrying in %v (attempt %v of %v): %v", delay, attempt, attempts, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+func getOptimizationBlockerData(queryVals recommendationsQueryValues, retries *retryBudget) (map[string]any, error) {
 	// execute query, process results
-	resp, err := uql.ClientV1.ExecuteQuery(&uql.Query{Str: query})
+	resp, err := uql.ClientV1.ExecuteQuery(optimizationStartedQuery(queryVals))
 	if err != nil {
 		return nil, fmt.Errorf("uql.ExecuteQuery: %w", err)
 	}
-	if resp.HasErrors() {
-		log.Error("Execution of optimization_started query encountered errors. Returned data may not be complete!")
-		for _, e := range resp.Errors() {
-			log.Errorf("%s: %s", e.Title, e.Detail)
-		}
+	if err := responseWarnings.checkResponse(resp, "Execution", "optimization_started query"); err != nil {
+		return nil, err
 	}
 
 	main_data_set := resp.Main()
 	if main_data_set == nil || len(main_data_set.Data) < 1 {
 		return nil, fmt.Errorf("no optimization_started results found for given input")
 	}
-	if len(main_data_set.Data[0]) < 1 {
-		return nil, fmt.Errorf("main dataset %v first row has no columns", main_data_set.Name)
-	}
 
-	data_set, ok := main_data_set.Data[0][0].(*uql.DataSet)
-	if !ok {
-		return nil, fmt.Errorf("main dataset %v first row first column (type %T) could not be converted to *uql.DataSet", main_data_set.Name, main_data_set.Data[0][0])
-	}
-	startedBlockersData, err := extractStartedBlockersData(data_set)
+	// collect the started events of all pages, otherwise recommendations of optimizations started in later pages
+	// would miss their blockers
+	startedBlockersData := make(map[string]any)
+	pages := uql.Pages{Nested: true, Continue: retries.continueQuery, Description: "optimization_started query", OnErrors: responseWarnings.onPageErrors("optimization_started query")}
+	_, err = pages.Iterate(resp, func(page int, dataSet *uql.DataSet) (bool, error) {
+		if err := extractStartedBlockersData(dataSet, startedBlockersData); err != nil {
+			return false, fmt.Errorf("page %v extractStartedBlockersData: %w", page, err)
+		}
+		return true, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("extractStartedBlockersData: %w", err)
+		return nil, err
 	}
 
 	return startedBlockersData, nil
 }
 
-func extractStartedBlockersData(dataset *uql.DataSet) (map[string]any, error) {
-
-	results := make(map[string]any)
-	if dataset == nil {
-		return results, nil
-	}
-	resp_data := &dataset.Data
-
-	for _, row := range *resp_data {
-
-		attributes := row[0].(uql.ComplexData)
-		attributesMap, _ := sliceToMap(attributes.Data)
+// extractStartedBlockersData adds the ignored blockers of each optimization_started event of the dataset to results,
+// keyed by optimizer ID and optimization number
+func extractStartedBlockersData(dataset *uql.DataSet, results map[string]any) error {
+	for index, row := range dataset.AllRows() {
+		attributesMap, err := eventAttributes(dataset, row)
+		if err != nil {
+			return fmt.Errorf("optimization_started row %v %w", index, err)
+		}
+		optimizerId, idOk := attributesMap["optimize.optimization.optimizer_id"].(string)
+		optimizationNum, numOk := attributesMap["optimize.optimization.num"].(string)
+		if !idOk || !numOk {
+			return fmt.Errorf("optimization_started row %v has no string optimizer ID and optimization number attributes", index)
+		}
 		newAttributes := make(map[string]any)
 
 		for attr, val := range attributesMap {
@@ -692,93 +1714,278 @@ func extractStartedBlockersData(dataset *uql.DataSet) (map[string]any, error) {
 				newAttributes[attr] = val
 			}
 		}
-		uniqueKey := fmt.Sprintf("%s-%s", attributesMap["optimize.optimization.optimizer_id"].(string), attributesMap["optimize.optimization.num"].(string))
+		uniqueKey := fmt.Sprintf("%s-%s", optimizerId, optimizationNum)
 		results[uniqueKey] = newAttributes
 	}
 
-	return results, nil
+	return nil
 }
 
 func extractEventsData(dataset *uql.DataSet) ([]EventsRow, error) {
-	if dataset == nil {
-		return []EventsRow{}, nil
-	}
-	resp_data := &dataset.Data
-	results := make([]EventsRow, 0, len(*resp_data))
+	rows := dataset.AllRows()
+	results := make([]EventsRow, 0, len(rows))
 
-	for _, row := range *resp_data {
-		attributes := row[0].(uql.ComplexData)
-		attributesMap, _ := sliceToMap(attributes.Data)
-		timestamp := row[1].(time.Time)
-		results = append(results, EventsRow{Timestamp: timestamp, EventAttributes: attributesMap})
+	for index, row := range rows {
+		attributesMap, err := eventAttributes(dataset, row)
+		if err != nil {
+			return results, fmt.Errorf("event row %v %w", index, err)
+		}
+		value, err := dataset.ColumnByName(row, "timestamp")
+		if err != nil {
+			return results, fmt.Errorf("event row %v %w", index, err)
+		}
+		timestamp, ok := value.(time.Time)
+		if !ok {
+			return results, fmt.Errorf("event row %v timestamp (type %T) could not be converted to time.Time", index, value)
+		}
+		normalizeNumericAttributes(attributesMap)
+		eventsRow := EventsRow{Timestamp: timestamp, EventAttributes: attributesMap}
+		eventsRow.IsProgress = isProgressEvent(eventsRow)
+		results = append(results, eventsRow)
 	}
 
 	return results, nil
 }
 
-type optimizationTemplateValues struct {
+// eventAttributes returns the attributes column of a row of fetched events as a map of attribute values
+func eventAttributes(dataset *uql.DataSet, row []any) (map[string]any, error) {
+	value, err := dataset.ColumnByName(row, "attributes")
+	if err != nil {
+		return nil, err
+	}
+	attributes, ok := value.(uql.ComplexData)
+	if !ok {
+		return nil, fmt.Errorf("attributes (type %T) could not be converted to uql.ComplexData", value)
+	}
+	attributesMap, _ := sliceToMap(attributes.Data)
+	return attributesMap, nil
+}
+
+// numericAttributes are reported as either numbers or strings depending on the event, they are normalized to
+// float64 so that their values can be compared and formatted consistently
+var numericAttributes = []string{
+	"optimize.recommendation.settings.cpu",
+	"optimize.recommendation.settings.memory",
+	"optimize.recommendation.settings.replicas",
+}
+
+// normalizeNumericAttributes coerces the numericAttributes present in the map to float64.
+// Unparseable values are left as-is
+func normalizeNumericAttributes(attributes map[string]any) {
+	for _, attr := range numericAttributes {
+		value, ok := attributes[attr]
+		if !ok {
+			continue
+		}
+		if number, ok := parseSetting(value); ok {
+			attributes[attr] = number
+		} else {
+			log.Debugf("Attribute %v value %v (type %T) could not be converted to a number", attr, value, value)
+		}
+	}
+}
+
+// presentNumericAttributes converts the numericAttributes of each row to the --unit units, then rounds them to the
+// --precision number of decimal places
+func (flags *eventsFlags) presentNumericAttributes(rows []EventsRow) {
+	flags.unitConversions.apply(rows)
+	if flags.precision < 0 {
+		return
+	}
+	scale := math.Pow10(flags.precision)
+	for _, row := range rows {
+		for _, attr := range numericAttributes {
+			if number, ok := row.EventAttributes[attr].(float64); ok {
+				row.EventAttributes[attr] = math.Round(number*scale) / scale
+			}
+		}
+	}
+}
+
+// expandEvents resolves the event types to retrieve from --events and --include-progress, less those of
+// --exclude-events. The first two flags are mutually exclusive, except for --events all, which already includes the
+// progress events. Event types may be given with their solution name qualifier, which is added back to the query
+func (flags *eventsCmdFlags) expandEvents(cmd *cobra.Command) error {
+	for i, event := range flags.events {
+		flags.events[i] = unqualifiedEventType(event)
+	}
+	for i, event := range flags.excludeEvents {
+		flags.excludeEvents[i] = unqualifiedEventType(event)
+	}
+	if slices.Contains(flags.events, allEventsToken) {
+		if len(flags.events) > 1 {
+			return fmt.Errorf("--events %v cannot be combined with specific event types", allEventsToken)
+		}
+		flags.events = append(append([]string{}, defaultEvents...), progressEvents...)
+	} else if flags.includeProgress {
+		if cmd.Flags().Changed("events") {
+			return fmt.Errorf("--include-progress cannot be combined with --events other than %q", allEventsToken)
+		}
+		flags.events = append(flags.events, progressEvents...)
+	}
+	return flags.applyEventExclusions()
+}
+
+// applyEventExclusions removes the --exclude-events types from the event types to retrieve. Excluding all of them
+// is an error naming the exclusions that emptied the set, rather than a query that silently returns nothing
+func (flags *eventsCmdFlags) applyEventExclusions() error {
+	if len(flags.excludeEvents) < 1 {
+		return nil
+	}
+	events := make([]string, 0, len(flags.events))
+	excluded := make([]string, 0, len(flags.excludeEvents))
+	for _, event := range flags.events {
+		if slices.Contains(flags.excludeEvents, event) {
+			if !slices.Contains(excluded, event) {
+				excluded = append(excluded, event)
+			}
+			continue
+		}
+		events = append(events, event)
+	}
+	if len(events) < 1 {
+		return fmt.Errorf("no event types are left to retrieve: --exclude-events %v excludes all of the event types selected by --events", strings.Join(excluded, ","))
+	}
+	flags.events = events
+	return nil
+}
+
+// eventType returns the event type of the row without its solution name qualifier, so that it compares equal to
+// the unqualified names of defaultEvents and progressEvents
+func eventType(row EventsRow) string {
+	name, _ := row.EventAttributes["appd.event.type"].(string)
+	return unqualifiedEventType(name)
+}
+
+// unqualifiedEventType strips the solution name qualifier, if any, from the event type name
+func unqualifiedEventType(name string) string {
+	if _, unqualified, found := strings.Cut(name, ":"); found {
+		return unqualified
+	}
+	return name
+}
+
+// isProgressEvent reports whether the event type of the row is one of progressEvents
+func isProgressEvent(row EventsRow) bool {
+	return slices.Contains(progressEvents, eventType(row))
+}
+
+type optimizationQueryValues struct {
 	Since        string
 	Until        string
 	SolutionName string
-	Filter       string
+	Filters      []string
+}
+
+func optimizationQuery(queryVals optimizationQueryValues) *uql.Query {
+	return uql.NewBuilder().
+		Since(queryVals.Since).
+		Until(queryVals.Until).
+		Fetch("attributes(optimize.optimization.optimizer_id), attributes(k8s.cluster.id), attributes(k8s.namespace.name), attributes(k8s.workload.name)").
+		From(fmt.Sprintf("entities(%v:optimization)", queryVals.SolutionName)).
+		Where(queryVals.Filters...).
+		Build()
+}
+
+// optimizationRow is an optimizer ID along with the attributes identifying the workload under optimization
+type optimizationRow struct {
+	OptimizerId  string
+	ClusterId    string
+	Namespace    string
+	WorkloadName string
 }
 
-var optimizationTemplate = template.Must(template.New("").Parse(`
-{{ with .Since }}SINCE {{ . }}
-{{ end -}}
-{{ with .Until }}UNTIL {{ . }}
-{{ end -}}
-FETCH attributes(optimize.optimization.optimizer_id)
-FROM entities({{ .SolutionName }}:optimization)[{{ .Filter }}]
-`))
+// defaultPrincipalAttribute is the attribute matched by --principal unless overridden with --principal-attr
+const defaultPrincipalAttribute = "optimize.principal.id"
+
+// checkPrincipal validates --principal-attr, which only applies along with --principal
+func (flags *eventsFlags) checkPrincipal(cmd *cobra.Command) error {
+	if cmd.Flags().Changed("principal-attr") && flags.principal == "" {
+		return errors.New("--principal-attr requires --principal")
+	}
+	if strings.TrimSpace(flags.principalAttr) == "" {
+		return errors.New("--principal-attr requires an attribute name, e.g., " + defaultPrincipalAttribute)
+	}
+	flags.principalAttr = strings.TrimSpace(flags.principalAttr)
+	return nil
+}
+
+// checkEntityFilter rejects an --entity-filter given without a predicate
+func (flags *eventsFlags) checkEntityFilter(cmd *cobra.Command) error {
+	if cmd.Flags().Changed("entity-filter") && strings.TrimSpace(flags.entityFilter) == "" {
+		return errors.New("--entity-filter requires a UQL predicate on the optimization entities, e.g., attributes(k8s.cluster.name) = 'prod'")
+	}
+	return nil
+}
 
-// listOptimizations takes applicable filter criteria from the eventsFlags and returns a list of applicable optimizer IDs
+// matchingOptimizations takes applicable filter criteria from the eventsFlags and returns the applicable optimizations
 // from the FMM entity optimize:optimization
-func listOptimizations(flags *eventsFlags) ([]string, error) {
-	tempVals := optimizationTemplateValues{
+func matchingOptimizations(flags *eventsFlags) ([]optimizationRow, error) {
+	if flags.namespace == "" && flags.workloadName == "" && flags.optimizerIdPrefix == "" && flags.entityFilter == "" {
+		return []optimizationRow{}, errors.New("sanity check failed, optimizations query must at least filter on namespace, workload name, optimizer ID prefix or entity filter, otherwise this query can be skipped")
+	}
+	rows, err := listOptimizationRows(flags)
+	results := make([]optimizationRow, 0, len(rows))
+	for _, row := range rows {
+		// UQL offers no prefix match operator, so the prefix is applied client-side to the matching optimizations
+		if strings.HasPrefix(row.OptimizerId, flags.optimizerIdPrefix) {
+			results = append(results, row)
+		}
+	}
+	return results, err
+}
+
+// listOptimizationRows takes applicable filter criteria from the eventsFlags and returns the matching optimizations
+// from the FMM entity optimize:optimization. Unlike matchingOptimizations, no filter criteria are required
+func listOptimizationRows(flags *eventsFlags) ([]optimizationRow, error) {
+	queryVals := optimizationQueryValues{
 		Since:        flags.since,
 		Until:        flags.until,
 		SolutionName: flags.solutionName,
 	}
 
-	filterList := make([]string, 0, 3)
+	filterList := make([]string, 0, 4)
 	if flags.namespace != "" {
-		filterList = append(filterList, fmt.Sprintf("attributes(\"k8s.namespace.name\") = %q", flags.namespace))
+		filterList = append(filterList, uql.AttributeEquals("k8s.namespace.name", flags.namespace))
 	}
-	if flags.workloadName != "" {
-		filterList = append(filterList, fmt.Sprintf("attributes(\"k8s.workload.name\") = %q", flags.workloadName))
+	if flags.entityFilter != "" {
+		// parenthesized so that the predicate combines with the other filters by AND regardless of its operators
+		filterList = append(filterList, fmt.Sprintf("(%v)", flags.entityFilter))
 	}
-	if len(filterList) < 1 {
-		return []string{}, errors.New("sanity check failed, optimizations query must at least filter on namespace or workload name, otherwise this query can be skipped")
+	if flags.workloadName != "" {
+		filterList = append(filterList, uql.AttributeEquals("k8s.workload.name", flags.workloadName))
 	}
 	if flags.clusterId != "" {
-		filterList = append(filterList, fmt.Sprintf("attributes(\"k8s.cluster.id\") = %q", flags.clusterId))
+		filterList = append(filterList, uql.AttributeEquals("k8s.cluster.id", flags.clusterId))
 	}
-	tempVals.Filter = strings.Join(filterList, " && ")
+	queryVals.Filters = filterList
 
-	var buff bytes.Buffer
-	if err := optimizationTemplate.Execute(&buff, tempVals); err != nil {
-		return []string{}, fmt.Errorf("optimizationTemplate.Execute: %w", err)
-	}
-	query := buff.String()
-
-	resp, err := uql.ClientV1.ExecuteQuery(&uql.Query{Str: query})
+	resp, err := uql.ClientV1.ExecuteQuery(optimizationQuery(queryVals))
 	if err != nil {
-		return []string{}, fmt.Errorf("uql.ClientV1.ExecuteQuery: %w", err)
+		return []optimizationRow{}, fmt.Errorf("uql.ClientV1.ExecuteQuery: %w", err)
 	}
-	if resp.HasErrors() {
-		log.Error("Execution of optimization query encountered errors. Returned data may not be complete!")
-		for _, e := range resp.Errors() {
-			log.Errorf("%s: %s", e.Title, e.Detail)
-		}
+	if err := responseWarnings.checkResponse(resp, "Execution", "optimization query"); err != nil {
+		return []optimizationRow{}, err
 	}
 
-	mainDataSet := resp.Main()
-	if mainDataSet == nil {
-		return []string{}, nil
-	}
-	results := make([]string, 0, len(mainDataSet.Data))
-	for index, row := range mainDataSet.Data {
+	results := []optimizationRow{}
+	pages := uql.Pages{Continue: flags.retries.continueQuery, Description: "optimization query", OnErrors: responseWarnings.onPageErrors("optimization query")}
+	_, err = pages.Iterate(resp, func(page int, dataSet *uql.DataSet) (bool, error) {
+		results, err = extractOptimizationRows(dataSet, results)
+		if err != nil {
+			return false, fmt.Errorf("page %v %w", page, err)
+		}
+		return true, nil
+	})
+	return results, err
+}
+
+// extractOptimizationRows appends the optimizations contained in the dataset to results. Only the optimizer ID
+// column is required, the workload attribute columns are left empty if missing or not strings
+func extractOptimizationRows(dataset *uql.DataSet, results []optimizationRow) ([]optimizationRow, error) {
+	// the columns are the attributes in the order of the fetch, read by position as their aliases are the fetched
+	// expressions
+	for index, row := range dataset.AllRows() {
 		if len(row) < 1 {
 			return results, fmt.Errorf("optimization data row %v has no columns", index)
 		}
@@ -786,41 +1993,13 @@ func listOptimizations(flags *eventsFlags) ([]string, error) {
 		if !ok {
 			return results, fmt.Errorf("optimization data row %v value %v (type %T) could not be converted to string", index, row[0], row[0])
 		}
-		results = append(results, idStr)
-	}
-
-	_, next_ok := mainDataSet.Links["next"]
-	for page := 2; next_ok; page++ {
-		resp, err = uql.ClientV1.ContinueQuery(mainDataSet, "next")
-		if err != nil {
-			return results, fmt.Errorf("page %v uql.ClientV1.ContinueQuery: %w", page, err)
-		}
-
-		if resp.HasErrors() {
-			log.Errorf("Continuation of optimization query (page %v) encountered errors. Returned data may not be complete!", page)
-			for _, e := range resp.Errors() {
-				log.Errorf("%s: %s", e.Title, e.Detail)
+		optRow := optimizationRow{OptimizerId: idStr}
+		for column, dest := range []*string{&optRow.ClusterId, &optRow.Namespace, &optRow.WorkloadName} {
+			if column+1 < len(row) {
+				*dest, _ = row[column+1].(string)
 			}
 		}
-		mainDataSet = resp.Main()
-		if mainDataSet == nil {
-			log.Errorf("Continuation of optimization query (page %v) has nil main data. Returned data may not be complete!", page)
-			break
-		}
-
-		for index, row := range mainDataSet.Data {
-			if len(row) < 1 {
-				return results, fmt.Errorf("page %v optimization data row %v has no columns", page, index)
-			}
-			idStr, ok := row[0].(string)
-			if !ok {
-				return results, fmt.Errorf("page %v optimization data row %v value %v (type %T) could not be converted to string", page, index, row[0], row[0])
-			}
-			results = append(results, idStr)
-		}
-
-		_, next_ok = mainDataSet.Links["next"]
+		results = append(results, optRow)
 	}
-
 	return results, nil
 }
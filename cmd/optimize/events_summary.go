@@ -0,0 +1,173 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/cmd/uql"
+	"github.com/cisco-open/fsoc/output"
+)
+
+type eventTypeCount struct {
+	EventType string
+	Count     int
+}
+
+// eventsSummaryQuery lets UQL aggregate the event counts per event type so that only counts are transferred
+func eventsSummaryQuery(queryVals eventsQueryValues) *uql.Query {
+	return uql.NewBuilder().
+		Since(queryVals.Since).
+		Until(queryVals.Until).
+		Fetch("events", queryVals.Events...).
+		Where(queryVals.Filters...).
+		Fields("attributes(appd.event.type)", "count()").
+		Build()
+}
+
+// queryEventTypeCounts retrieves the per event type counts using a UQL aggregation. Any error, including
+// errors reported within the response and unexpected response shapes, indicates that the caller should fall back
+// to counting the retrieved events with countEventTypes
+func queryEventTypeCounts(queryVals eventsQueryValues) ([]eventTypeCount, error) {
+	resp, err := uql.ClientV1.ExecuteQuery(eventsSummaryQuery(queryVals))
+	if err != nil {
+		return nil, fmt.Errorf("uql.ClientV1.ExecuteQuery: %w", err)
+	}
+	if resp.HasErrors() {
+		return nil, uql.Errors(resp.Errors())
+	}
+
+	main_data_set := resp.Main()
+	if main_data_set == nil || len(main_data_set.Data) < 1 {
+		return []eventTypeCount{}, nil
+	}
+	data_set, err := uql.FirstNestedDataSet(resp)
+	if err != nil {
+		return nil, err
+	}
+	if data_set == nil {
+		return []eventTypeCount{}, nil
+	}
+	if _, ok := data_set.Links["next"]; ok {
+		return nil, errors.New("aggregated event counts unexpectedly span multiple pages")
+	}
+
+	counts := make([]eventTypeCount, 0, len(data_set.Data))
+	for index, row := range data_set.Data {
+		if len(row) < 2 {
+			return nil, fmt.Errorf("aggregation row %v has %v columns, expected event type and count", index, len(row))
+		}
+		eventType, ok := row[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("aggregation row %v event type (type %T) could not be converted to string", index, row[0])
+		}
+		count, err := toCount(row[1])
+		if err != nil {
+			return nil, fmt.Errorf("aggregation row %v: %w", index, err)
+		}
+		counts = append(counts, eventTypeCount{EventType: eventType, Count: count})
+	}
+	sortEventTypeCounts(counts)
+	return counts, nil
+}
+
+func toCount(value any) (int, error) {
+	switch typed := value.(type) {
+	case int:
+		return typed, nil
+	case float64:
+		return int(typed), nil
+	case string:
+		return strconv.Atoi(typed)
+	}
+	return 0, fmt.Errorf("count (type %T) could not be converted to int", value)
+}
+
+// countEventTypes counts the retrieved events per event type on the client side
+func countEventTypes(rows []EventsRow) []eventTypeCount {
+	byType := make(map[string]int)
+	for _, row := range rows {
+		byType[fmt.Sprintf("%v", row.EventAttributes["appd.event.type"])]++
+	}
+	counts := make([]eventTypeCount, 0, len(byType))
+	for eventType, count := range byType {
+		counts = append(counts, eventTypeCount{EventType: eventType, Count: count})
+	}
+	sortEventTypeCounts(counts)
+	return counts
+}
+
+// sortEventTypeCounts orders the counts with the most frequent event types first
+func sortEventTypeCounts(counts []eventTypeCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].EventType < counts[j].EventType
+	})
+}
+
+func printEventTypeCounts(cmd *cobra.Command, counts []eventTypeCount) {
+	lines := make([][]string, 0, len(counts))
+	for _, count := range counts {
+		lines = append(lines, []string{count.EventType, strconv.Itoa(count.Count)})
+	}
+	output.PrintCmdOutputCustom(cmd, struct {
+		Items    []eventTypeCount `json:"items"`
+		Total    int              `json:"total"`
+		Warnings []queryWarning   `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+	}{Items: counts, Total: len(counts), Warnings: responseWarnings.drain()}, &output.Table{
+		Headers: []string{"EventType", "Count"},
+		Lines:   lines,
+	})
+}
+
+func totalEventCount(counts []eventTypeCount) int {
+	total := 0
+	for _, count := range counts {
+		total += count.Count
+	}
+	return total
+}
+
+// printEventCount outputs the --count-only total, as a plain integer for human formats
+func (flags *eventsCmdFlags) printEventCount(cmd *cobra.Command, total int) error {
+	if flags.failOnEmpty && total < 1 {
+		return errNoResults
+	}
+	if format, _ := cmd.Flags().GetString("output"); format == "json" || format == "json-compact" || format == "yaml" {
+		output.PrintCmdOutput(cmd, struct {
+			Total    int            `json:"total"`
+			Warnings []queryWarning `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+		}{Total: total, Warnings: responseWarnings.drain()})
+		return nil
+	}
+	output.PrintCmdStatus(cmd, fmt.Sprintf("%v\n", total))
+	return nil
+}
+
+// noResults reports that nothing matched the given input. With --count-only, a zero total is output instead of
+// the message, see eventsFlags.noResults
+func (flags *eventsCmdFlags) noResults(cmd *cobra.Command, message string) error {
+	if flags.countOnly {
+		return flags.printEventCount(cmd, 0)
+	}
+	return flags.eventsFlags.noResults(cmd, message)
+}
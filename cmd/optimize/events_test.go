@@ -0,0 +1,245 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// updateGolden rewrites the golden files with the queries produced, e.g., go test ./cmd/optimize -run Query -update
+var updateGolden = flag.Bool("update", false, "update the golden files of the query tests")
+
+// fixedClock sets the clock resolving windows and calendar anchors to a Wednesday for the duration of the test
+func fixedClock(t *testing.T) {
+	now := time.Date(2023, time.August, 16, 14, 30, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = time.Now })
+}
+
+// assertGolden compares the query with the golden file testdata/queries/<name>.uql
+func assertGolden(t *testing.T, name string, query string) {
+	path := filepath.Join("testdata", "queries", name+".uql")
+	if *updateGolden {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(query), 0644))
+	}
+	golden, err := os.ReadFile(path)
+	require.NoError(t, err, "missing golden file, run the test with -update to create it")
+	assert.Equal(t, string(golden), query)
+}
+
+// resolveTo returns a resolver of the given optimizer IDs
+func resolveTo(optimizerIds []string) optimizerResolver {
+	return func() ([]string, error) {
+		return optimizerIds, nil
+	}
+}
+
+// parseEventsFlags parses the events command arguments and prepares the flags as listEvents does before building
+// its query
+func parseEventsFlags(t *testing.T, args []string) (*eventsCmdFlags, error) {
+	flags := &eventsCmdFlags{}
+	command := newCmdEvents(flags)
+	require.NoError(t, command.ParseFlags(args))
+	if err := flags.applyWindow(); err != nil {
+		return nil, err
+	}
+	if err := flags.expandEvents(command); err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+func TestBuildEventsQuery(t *testing.T) {
+	fixedClock(t)
+	tests := []struct {
+		name         string
+		args         []string
+		optimizerIds []string
+	}{
+		{name: "events-default"},
+		{name: "events-optimizer-id-count", args: []string{"--optimizer-id", "ns-name-00000000-0000-0000-0000-000000000000", "--count", "5"}},
+		{name: "events-resolved-optimizers", args: []string{"--namespace", "ns", "--cluster-id", "cluster"}, optimizerIds: []string{"ns-a-1", "ns-b-2"}},
+		{name: "events-all-principal", args: []string{"--events", "all", "--principal", "user@example.com", "--since", "-7d", "--until", "-1d"}},
+		{name: "events-include-progress-excluded", args: []string{"--include-progress", "--exclude-events", "experiment_progress,stage_ended", "--count", "2500", "--page-size", "200"}},
+		{name: "events-window-yesterday", args: []string{"--window", "yesterday", "--timezone", "Europe/Paris"}},
+		{name: "events-range-anchors", args: []string{"--range", "@month..@week", "--timezone", "UTC", "--events", "recommendation_verified"}},
+		{name: "events-unix-timestamps", args: []string{"--since", "1692000000", "--until", "1692100000000"}},
+		{name: "events-qualified", args: []string{"--events", "optimize:optimization_ended,stage_ended", "--exclude-events", "optimize:stage_ended"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flags, err := parseEventsFlags(t, test.args)
+			require.NoError(t, err)
+			flags.resolver = resolveTo(test.optimizerIds)
+			query, err := buildEventsQuery(flags)
+			require.NoError(t, err)
+			assertGolden(t, test.name, query)
+		})
+	}
+}
+
+func TestBuildEventsQueryErrors(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{args: []string{"--count", "0"}, expected: "counts must be positive"},
+		{args: []string{"--page-size", "1001"}, expected: "page sizes must be between 1 and 1000"},
+		{args: []string{"--events", "stage_ended", "--exclude-events", "stage_ended"}, expected: "--exclude-events stage_ended excludes all"},
+		{args: []string{"--since", "@year"}, expected: "unknown calendar anchor"},
+		{args: []string{"--namespace", "empty"}, expected: errNoOptimizations.Error()},
+	}
+	for _, test := range tests {
+		flags, err := parseEventsFlags(t, test.args)
+		if err == nil {
+			flags.resolver = resolveTo(nil)
+			_, err = buildEventsQuery(flags)
+		}
+		assert.ErrorContains(t, err, test.expected, test.args)
+	}
+}
+
+func TestBuildRecommendationsQuery(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		optimizerIds []string
+	}{
+		{name: "recommendations-default"},
+		{name: "recommendations-include-invalidated", args: []string{"--optimizer-id", "ns-name-1", "--include-invalidated", "--count", "20"}},
+		{name: "recommendations-compare-optimizer", args: []string{"--optimizer-id", "ns-a-1", "--compare-optimizer", "ns-b-2", "--count", "2000"}},
+		{name: "recommendations-resolved-principal", args: []string{"--workload-name", "w", "--principal", "user@example.com"}, optimizerIds: []string{"ns-w-1"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flags := &recommendationsCmdFlags{}
+			require.NoError(t, newCmdRecommendations(flags).ParseFlags(test.args))
+			flags.resolver = resolveTo(test.optimizerIds)
+			query, err := buildRecommendationsQuery(flags)
+			require.NoError(t, err)
+			assertGolden(t, test.name, query)
+
+			// the blockers query shares the optimizer filters, but not the principal one
+			blockerVals, _, err := buildRecommendationsQueryValues(flags)
+			require.NoError(t, err)
+			assertGolden(t, test.name+"-blockers", optimizationStartedQuery(blockerVals).Str)
+		})
+	}
+}
+
+func TestLatestRecommendationQuery(t *testing.T) {
+	flags, err := parseEventsFlags(t, []string{"--optimizer-id", "ns-name-1", "--since-latest-recommendation"})
+	require.NoError(t, err)
+	assertGolden(t, "events-latest-recommendation", recommendationsQuery(latestRecommendationQueryValues(&flags.eventsFlags)).Str)
+}
+
+func TestHtmlFollowRejected(t *testing.T) {
+	flags := &eventsCmdFlags{}
+	command := newCmdEvents(flags)
+	command.Flags().String("output", "html", "")
+	command.SetArgs([]string{"--follow"})
+	command.SilenceUsage, command.SilenceErrors = true, true
+	assert.ErrorContains(t, command.Execute(), "-o html writes a single report and cannot be combined with --follow")
+}
+
+func TestAggregatesCounts(t *testing.T) {
+	tests := []struct {
+		args       []string
+		aggregates bool
+	}{
+		{args: []string{"--summary"}, aggregates: true},
+		{args: []string{"--count-only"}, aggregates: true},
+		{args: []string{"--list-types"}, aggregates: true},
+		{args: []string{"--summary", "--count", "10"}},
+		{args: []string{"--summary", "--no-progress"}},
+		{args: []string{"--list-types", "--only-progress"}},
+		{args: []string{"--count-only", "--exclude-progress"}},
+		{args: []string{"--summary", "--has", "optimize.principal.id"}},
+	}
+	for _, test := range tests {
+		flags, err := parseEventsFlags(t, test.args)
+		require.NoError(t, err, test.args)
+		assert.Equal(t, test.aggregates, flags.aggregatesCounts(), test.args)
+	}
+}
+
+func TestResolveOptimizersOnce(t *testing.T) {
+	calls := 0
+	flags := &recommendationsCmdFlags{}
+	require.NoError(t, newCmdRecommendations(flags).ParseFlags([]string{"--namespace", "ns"}))
+	flags.resolver = func() ([]string, error) {
+		calls++
+		return []string{"ns-a-1", "ns-b-2"}, nil
+	}
+
+	_, err := buildRecommendationsQuery(flags)
+	require.NoError(t, err)
+	blockerVals, _, err := buildRecommendationsQueryValues(flags)
+	require.NoError(t, err)
+	assert.Contains(t, optimizationStartedQuery(blockerVals).Str, "ns-b-2")
+	assert.Equal(t, 1, calls, "the recommendations and the blockers queries resolve the optimizer IDs only once")
+}
+
+func TestFollowBackoff(t *testing.T) {
+	backoff := (&eventsFlags{followInterval: time.Second, followMaxInterval: 5 * time.Second}).newFollowBackoff()
+
+	delays := []time.Duration{}
+	for i := 0; i < 4; i++ {
+		delays = append(delays, backoff.next())
+	}
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}, delays)
+
+	// events arrived, the --follow-interval is restored before doubling again
+	backoff.reset()
+	assert.Equal(t, time.Second, backoff.next())
+	assert.Equal(t, 2*time.Second, backoff.next())
+
+	constant := (&eventsFlags{followInterval: time.Second}).newFollowBackoff()
+	assert.Equal(t, time.Second, constant.next())
+	assert.Equal(t, time.Second, constant.next(), "no backoff without --follow-max-interval")
+}
+
+func TestNewFollowStop(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{args: []string{"--follow", "--until-event", "recommendation_verified"}},
+		{args: []string{"--follow", "--until-event", "optimize:recommendation_verified"}},
+		{args: []string{"--follow", "--events", "optimize:optimization_ended", "--until-event", "optimization_ended"}},
+		{args: []string{"--until-event", "recommendation_verified"}, expected: "--until-event requires --follow"},
+		{args: []string{"--follow", "--until-event", "optimize:stage_progress"}, expected: "is not one of the retrieved event types"},
+	}
+	for _, test := range tests {
+		flags, err := parseEventsFlags(t, test.args)
+		require.NoError(t, err, test.args)
+		stop, err := flags.newFollowStop(nil)
+		if test.expected != "" {
+			assert.ErrorContains(t, err, test.expected, test.args)
+			continue
+		}
+		require.NoError(t, err, test.args)
+		verified := EventsRow{EventAttributes: map[string]any{"appd.event.type": "optimize:" + stop.eventType}}
+		assert.True(t, stop.reached([]EventsRow{verified}), test.args)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/output"
+)
+
+// experimentRow is an experiment folded from its experiment_started and experiment_ended events
+type experimentRow struct {
+	OptimizerId string
+	Experiment  any
+	Status      string
+	Start       *time.Time     `json:",omitempty" yaml:",omitempty"`
+	End         *time.Time     `json:",omitempty" yaml:",omitempty"`
+	Duration    string         `json:",omitempty" yaml:",omitempty"`
+	Attributes  map[string]any `json:",omitempty" yaml:",omitempty"`
+}
+
+// foldExperiments correlates the experiment_started and experiment_ended events by optimizer ID and
+// optimization/stage/experiment numbers into one row per experiment, merging the attributes of both events.
+// Rows are expected in ascending timestamp order. Experiments without an ended event are reported as running,
+// those whose started event precedes the time window are reported without a start
+func foldExperiments(rows []EventsRow) []*experimentRow {
+	experiments := make([]*experimentRow, 0)
+	running := make(map[string]*experimentRow)
+	for _, row := range rows {
+		event := eventType(row)
+		if event != "experiment_started" && event != "experiment_ended" {
+			continue
+		}
+		key := spanKey("experiment", row)
+		timestamp := row.Timestamp
+		if event == "experiment_started" {
+			experiment := newExperimentRow(row)
+			experiment.Status = "running"
+			experiment.Start = &timestamp
+			running[key] = experiment
+			experiments = append(experiments, experiment)
+			continue
+		}
+
+		experiment, ok := running[key]
+		if ok {
+			delete(running, key)
+		} else {
+			experiment = newExperimentRow(row)
+			experiments = append(experiments, experiment)
+		}
+		experiment.Status = "ended"
+		experiment.End = &timestamp
+		mergeExperimentAttributes(experiment, row)
+		if experiment.Start != nil {
+			experiment.Duration = timestamp.Sub(*experiment.Start).Round(time.Second).String()
+		}
+	}
+	return experiments
+}
+
+func newExperimentRow(row EventsRow) *experimentRow {
+	experiment := &experimentRow{
+		OptimizerId: fmt.Sprintf("%v", row.EventAttributes["optimize.optimization.optimizer_id"]),
+		Experiment:  row.EventAttributes["optimize.experiment.num"],
+		Attributes:  make(map[string]any, len(row.EventAttributes)),
+	}
+	mergeExperimentAttributes(experiment, row)
+	return experiment
+}
+
+// mergeExperimentAttributes adds the event's attributes to the experiment's, the event type excepted as it
+// differs between the folded events
+func mergeExperimentAttributes(experiment *experimentRow, row EventsRow) {
+	for key, value := range row.EventAttributes {
+		if key != "appd.event.type" {
+			experiment.Attributes[key] = value
+		}
+	}
+}
+
+func printExperimentRows(cmd *cobra.Command, experiments []*experimentRow) {
+	lines := make([][]string, 0, len(experiments))
+	for _, experiment := range experiments {
+		start, end := "", ""
+		if experiment.Start != nil {
+			start = experiment.Start.Format(time.RFC3339)
+		}
+		if experiment.End != nil {
+			end = experiment.End.Format(time.RFC3339)
+		}
+		experimentNum := ""
+		if experiment.Experiment != nil {
+			experimentNum = fmt.Sprintf("%v", experiment.Experiment)
+		}
+		lines = append(lines, []string{experiment.OptimizerId, experimentNum, experiment.Status, start, end, experiment.Duration})
+	}
+	output.PrintCmdOutputCustom(cmd, struct {
+		Items []*experimentRow `json:"items"`
+		Total int              `json:"total"`
+	}{Items: experiments, Total: len(experiments)}, &output.Table{
+		Headers: []string{"OptimizerId", "Experiment", "Status", "Start", "End", "Duration"},
+		Lines:   lines,
+	})
+}
@@ -0,0 +1,212 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/output"
+)
+
+// explanation collects the sentences of an --explain description
+type explanation struct {
+	lines []string
+}
+
+func (e *explanation) add(format string, a ...any) {
+	e.lines = append(e.lines, fmt.Sprintf(format, a...))
+}
+
+func (e *explanation) print(cmd *cobra.Command) {
+	output.PrintCmdStatus(cmd, "- "+strings.Join(e.lines, "\n- ")+"\n")
+}
+
+// explainWindow describes the time interval, given the default start of the command
+func (flags *eventsFlags) explainWindow(e *explanation, defaultSince string) {
+	since, until := flags.since, flags.until
+	if since == "" {
+		since = defaultSince + " (default)"
+	}
+	if until == "" {
+		until = "now"
+	}
+	if flags.window != "" {
+		e.add("Time window %q: from %v until %v", flags.window, since, until)
+		return
+	}
+	e.add("Time window: from %v until %v", since, until)
+}
+
+// explainFilters describes how the retrieved rows, named noun, are constrained to optimizers, without resolving the optimizers
+func (flags *eventsFlags) explainFilters(e *explanation, noun string) {
+	if flags.clusterId != "" {
+		e.add("Only %v of cluster %v are retrieved", noun, flags.clusterId)
+	}
+	if flags.principal != "" {
+		e.add("Only %v whose %v is %v are retrieved", noun, flags.principalAttr, flags.principal)
+	}
+	if flags.optimizerIdFile != "" {
+		if flags.optimizerId != "" {
+			e.add("Only %v of optimizer %v and of the optimizers listed in %v are retrieved", noun, flags.optimizerId, flags.optimizerIdFile)
+		} else {
+			e.add("Only %v of the optimizers listed in %v are retrieved", noun, flags.optimizerIdFile)
+		}
+		return
+	}
+	if flags.optimizerId != "" {
+		e.add("Only %v of optimizer %v are retrieved", noun, flags.optimizerId)
+		return
+	}
+	criteria := make([]string, 0, 3)
+	if flags.namespace != "" {
+		criteria = append(criteria, fmt.Sprintf("namespace %v", flags.namespace))
+	}
+	if flags.workloadName != "" {
+		criteria = append(criteria, fmt.Sprintf("workload %v", flags.workloadName))
+	}
+	if flags.optimizerIdPrefix != "" {
+		criteria = append(criteria, fmt.Sprintf("optimizer ID prefix %v", flags.optimizerIdPrefix))
+	}
+	if flags.entityFilter != "" {
+		criteria = append(criteria, fmt.Sprintf("entity filter %v", flags.entityFilter))
+	}
+	if len(criteria) < 1 {
+		e.add("The %v of all optimizers are retrieved", noun)
+		return
+	}
+	e.add("A first query looks up the optimizations matching %v, and only the %v of their optimizers are retrieved", strings.Join(criteria, " and "), noun)
+	if flags.interactive {
+		e.add("If several optimizations match, you are prompted to select among them")
+	}
+}
+
+// explainClientFilters describes the filters applied after retrieval, which UQL can't express
+func (flags *eventsFlags) explainClientFilters(e *explanation) {
+	if len(flags.has) > 0 {
+		e.add("Rows lacking any of the attributes %v are dropped after retrieval", strings.Join(flags.has, ", "))
+	}
+	if len(flags.missing) > 0 {
+		e.add("Rows carrying any of the attributes %v are dropped after retrieval", strings.Join(flags.missing, ", "))
+	}
+	for _, r := range flags.attrRanges {
+		e.add("Rows are dropped after retrieval unless %v, regardless of when they were emitted", r.describe())
+	}
+	if flags.sortBy != "" {
+		order := "ascending"
+		if flags.sortDesc {
+			order = "descending"
+		}
+		e.add("Rows are sorted by %v in %v order after retrieval", flags.sortBy, order)
+	}
+}
+
+// explainCount describes how many rows are requested and whether results are paginated
+func (flags *eventsFlags) explainCount(e *explanation, noun string) {
+	switch {
+	case flags.count == -1:
+		e.add("All matching %v are retrieved, following as many result pages as needed", noun)
+	case flags.count > maxLimitsCount:
+		e.add("Up to %v %v are retrieved across multiple result pages, as a single page holds at most %v", flags.count, noun, maxLimitsCount)
+	default:
+		e.add("The number of %v retrieved is limited to %v, in a single query", noun, flags.count)
+	}
+}
+
+// explainEvents prints what the events command will do instead of doing it
+func (flags *eventsCmdFlags) explainEvents(cmd *cobra.Command) {
+	e := &explanation{}
+	flags.explainWindow(e, "-1h")
+	e.add("Event types: %v", strings.Join(flags.events, ", "))
+	if flags.onlyProgress {
+		e.add("Only progress events are output")
+	} else if flags.noProgress {
+		e.add("Progress events are dropped from the output")
+	}
+	if flags.excludeProgress {
+		e.add("Events of the progress event types (%v) are dropped from the output", strings.Join(progressEvents, ", "))
+	}
+	flags.explainFilters(e, "events")
+	if flags.pageSize != -1 {
+		e.add("Results are requested %v events per page", flags.pageSize)
+	}
+	flags.explainCount(e, "events")
+	flags.explainClientFilters(e)
+
+	switch {
+	case flags.summary:
+		e.add("Only the number of events per event type is output, aggregated by UQL when possible")
+	case flags.countOnly:
+		e.add("Only the total number of events is output, aggregated by UQL when possible")
+	case flags.experiments:
+		e.add("Experiment started and ended events are folded into one row per experiment")
+	case flags.outputDir != "":
+		e.add("The events of each optimizer are written to their own file in %v", flags.outputDir)
+	}
+
+	if flags.follow {
+		interval := fmt.Sprintf("every %v", flags.followInterval)
+		if flags.followMaxInterval > 0 {
+			interval += fmt.Sprintf(", backing off up to %v while no new events arrive", flags.followMaxInterval)
+		}
+		e.add("After the initial results, new events are followed %v", interval)
+		if flags.followEach {
+			e.add("Each matching optimizer is followed with its own cursor")
+		}
+		if flags.followMaxDuration > 0 {
+			e.add("Following stops after %v", flags.followMaxDuration)
+		} else {
+			e.add("Following continues until interrupted")
+		}
+	}
+	e.print(cmd)
+}
+
+// explainRecommendations prints what the recommendations command will do instead of doing it
+func (flags *recommendationsCmdFlags) explainRecommendations(cmd *cobra.Command) {
+	e := &explanation{}
+	flags.explainWindow(e, recommendationsLookbackSince)
+	if flags.includeInvalidated {
+		e.add("Identified, verified and invalidated recommendations are retrieved")
+	} else {
+		e.add("Only verified recommendations are retrieved")
+	}
+	flags.explainFilters(e, "recommendations")
+	flags.explainCount(e, "recommendations")
+	e.add("A second query retrieves the optimization_started events of the recommended optimizers to report their blockers")
+	if flags.onlyBlocked {
+		e.add("Only recommendations with blockers are output")
+	} else if flags.onlyUnblocked {
+		e.add("Only recommendations without blockers are output")
+	}
+	for _, threshold := range flags.settingThresholds() {
+		if cmd.Flags().Changed(threshold.minFlag) {
+			e.add("Recommendations with %v below %v are dropped", threshold.attribute, threshold.min)
+		}
+		if cmd.Flags().Changed(threshold.maxFlag) {
+			e.add("Recommendations with %v above %v are dropped", threshold.attribute, threshold.max)
+		}
+	}
+	flags.explainClientFilters(e)
+	if flags.exportPatch {
+		e.add("Kubernetes patches are output instead of the recommendations, looking up each optimizer's configuration")
+	}
+	if flags.savingsReport {
+		e.add("The savings of the latest verified recommendation of each workload are summed instead of outputting the recommendations")
+	}
+	e.print(cmd)
+}
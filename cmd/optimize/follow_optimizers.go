@@ -0,0 +1,213 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/cmd/uql"
+)
+
+// optimizerFollowCursor is the follow cursor of the events query constrained to a single optimizer
+type optimizerFollowCursor struct {
+	optimizerId string
+	data_set    *uql.DataSet
+}
+
+type followRoundResult struct {
+	err             error
+	cursorExhausted bool
+	stopReached     bool
+}
+
+// followOptimizers follows the events of each optimizer with its own follow cursor rather than a single cursor for
+// the whole IN clause, so that the follow window of less active optimizers isn't missed. Cursors are continued
+// concurrently and each round of results is merged by timestamp before printing. The cursors start at the latest
+// event already printed; events returned again at that boundary are dropped by dedup
+func followOptimizers(cmd *cobra.Command, flags *eventsCmdFlags, queryVals eventsQueryValues, baseFilters []string, optimizerIds []string, dedup *eventDeduplicator, printedRows []EventsRow) error {
+	queryVals = followFromLatest(queryVals, printedRows)
+
+	cursors := make([]*optimizerFollowCursor, len(optimizerIds))
+	initialRows := make([][]EventsRow, len(optimizerIds))
+	errs := make([]error, len(optimizerIds))
+	var wg sync.WaitGroup
+	for i, optimizerId := range optimizerIds {
+		wg.Add(1)
+		go func(i int, optimizerId string) {
+			defer wg.Done()
+			optimizerQueryVals := queryVals
+			optimizerQueryVals.Filters = append(append([]string{}, baseFilters...), uql.AttributeEquals("optimize.optimization.optimizer_id", optimizerId))
+			var data_set *uql.DataSet
+			data_set, initialRows[i], errs[i] = startOptimizerFollowCursor(optimizerQueryVals)
+			if data_set != nil {
+				cursors[i] = &optimizerFollowCursor{optimizerId: optimizerId, data_set: data_set}
+			}
+		}(i, optimizerId)
+	}
+	wg.Wait()
+
+	activeCursors := make([]*optimizerFollowCursor, 0, len(cursors))
+	rows := make([]EventsRow, 0)
+	for i, optimizerId := range optimizerIds {
+		if errs[i] != nil {
+			return fmt.Errorf("optimizer %q startOptimizerFollowCursor: %w", optimizerId, errs[i])
+		}
+		if cursors[i] == nil {
+			log.Warnf("Events query for optimizer %q returned no follow cursor, its events will not be followed", optimizerId)
+			continue
+		}
+		activeCursors = append(activeCursors, cursors[i])
+		rows = append(rows, initialRows[i]...)
+	}
+	sortByTimestamp(rows)
+	if printFollowedRows(cmd, rows, dedup, flags) {
+		flags.stop.printStopped(cmd)
+		return nil
+	}
+
+	// setup async channels
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	roundChan := make(chan *followRoundResult, 1)
+	roundChan <- &followRoundResult{}
+	deadline := flags.followDeadline()
+	backoff := flags.newFollowBackoff()
+	reconnects := flags.newFollowReconnects()
+
+	for {
+		select {
+		case <-signals:
+			// exit requested
+			return nil
+		case <-deadline:
+			flags.printFollowStopped(cmd)
+			return nil
+		case roundResult := <-roundChan:
+			reconnectDelay, err := reconnects.check(roundResult.err)
+			if err != nil {
+				return err
+			}
+			if roundResult.stopReached {
+				flags.stop.printStopped(cmd)
+				return nil
+			}
+			// run in background to allow interrupts, waiting only once every cursor has been exhausted
+			go func() {
+				if roundResult.err != nil {
+					time.Sleep(reconnectDelay)
+				} else if roundResult.cursorExhausted {
+					backoff.sleep()
+				} else {
+					backoff.reset()
+				}
+				// the rows of the cursors continued successfully are printed even if another one failed
+				rows, cursorExhausted, err := followOptimizersRound(activeCursors)
+				stopReached := printFollowedRows(cmd, rows, dedup, flags)
+				roundChan <- &followRoundResult{err: err, cursorExhausted: cursorExhausted, stopReached: stopReached}
+			}()
+		}
+	}
+}
+
+// followFromLatest returns the query values of follow cursors starting at the latest event already printed, with
+// the default page size
+func followFromLatest(queryVals eventsQueryValues, printedRows []EventsRow) eventsQueryValues {
+	var latest time.Time
+	for _, row := range printedRows {
+		if row.Timestamp.After(latest) {
+			latest = row.Timestamp
+		}
+	}
+	if !latest.IsZero() {
+		queryVals.Since = latest.Format(time.RFC3339Nano)
+	}
+	queryVals.Limits = 0
+	return queryVals
+}
+
+// startOptimizerFollowCursor executes the events query and returns the events dataset, whose links provide the
+// follow cursor, along with its rows. A nil dataset is returned if the query produced no events dataset
+func startOptimizerFollowCursor(queryVals eventsQueryValues) (*uql.DataSet, []EventsRow, error) {
+	resp, err := uql.ClientV1.ExecuteQuery(eventsQuery(queryVals))
+	if err != nil {
+		return nil, nil, fmt.Errorf("uql.ClientV1.ExecuteQuery: %w", err)
+	}
+	if err := responseWarnings.checkResponse(resp, "Execution", "events query"); err != nil {
+		return nil, nil, err
+	}
+
+	main_data_set := resp.Main()
+	if main_data_set == nil || len(main_data_set.Data) < 1 || len(main_data_set.Data[0]) < 1 {
+		return nil, nil, nil
+	}
+	data_set, err := uql.FirstNestedDataSet(resp)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := extractEventsData(data_set)
+	if err != nil {
+		return nil, nil, fmt.Errorf("extractEventsData: %w", err)
+	}
+	return data_set, rows, nil
+}
+
+// followOptimizersRound continues all follow cursors concurrently, advancing them in place, and returns the
+// merged rows in timestamp order. cursorExhausted is set once none of the cursors returned new rows. Should some
+// cursors fail, the rows of the others are returned along with the first failure, the failed cursors being left
+// in place to be continued again
+func followOptimizersRound(cursors []*optimizerFollowCursor) ([]EventsRow, bool, error) {
+	results := make([]*followEventResult, len(cursors))
+	var wg sync.WaitGroup
+	for i, cursor := range cursors {
+		wg.Add(1)
+		go func(i int, cursor *optimizerFollowCursor) {
+			defer wg.Done()
+			results[i] = followDataset(cursor.data_set)
+		}(i, cursor)
+	}
+	wg.Wait()
+
+	rows := make([]EventsRow, 0)
+	cursorExhausted := true
+	var err error
+	for i, result := range results {
+		if result.err != nil {
+			if err == nil {
+				err = fmt.Errorf("optimizer %q: %w", cursors[i].optimizerId, result.err)
+			}
+			continue
+		}
+		cursors[i].data_set = result.data_set
+		rows = append(rows, result.rows...)
+		cursorExhausted = cursorExhausted && result.cursorExhausted
+	}
+	sortByTimestamp(rows)
+	return rows, cursorExhausted && err == nil, err
+}
+
+func sortByTimestamp(rows []EventsRow) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Timestamp.Before(rows[j].Timestamp)
+	})
+}
@@ -0,0 +1,164 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/cmd/uql"
+)
+
+// progressFollowCursor is one of the follow cursors of followProgressSeparately, continued at its own interval
+type progressFollowCursor struct {
+	description string
+	data_set    *uql.DataSet
+	backoff     *followBackoff
+}
+
+// progressFollowResult is the result of continuing one of the cursors of followProgressSeparately
+type progressFollowResult struct {
+	cursor *progressFollowCursor
+	*followEventResult
+}
+
+// checkProgressFollowInterval validates --progress-follow-interval, which requires following both lifecycle and
+// progress events
+func (flags *eventsCmdFlags) checkProgressFollowInterval(cmd *cobra.Command) error {
+	if !cmd.Flags().Changed("progress-follow-interval") {
+		return nil
+	}
+	if !flags.follow || flags.progressPeriod <= 0 {
+		return errors.New("--progress-follow-interval requires --follow and a positive duration")
+	}
+	lifecycleEvents, progressEvents := splitProgressEvents(flags.events)
+	if len(lifecycleEvents) < 1 || len(progressEvents) < 1 {
+		return errors.New("--progress-follow-interval requires both lifecycle and progress events, e.g., with --include-progress")
+	}
+	return nil
+}
+
+// splitProgressEvents separates the event types, with or without their solution name qualifier, into the
+// lifecycle and the progress events
+func splitProgressEvents(events []string) ([]string, []string) {
+	var lifecycleEvents, progress []string
+	for _, event := range events {
+		name := event
+		if _, unqualified, found := strings.Cut(event, ":"); found {
+			name = unqualified
+		}
+		if slices.Contains(progressEvents, name) {
+			progress = append(progress, event)
+		} else {
+			lifecycleEvents = append(lifecycleEvents, event)
+		}
+	}
+	return lifecycleEvents, progress
+}
+
+// followProgressSeparately follows the lifecycle events at --follow-interval and the progress events, which are
+// produced at a much higher cadence, with their own cursor at --progress-follow-interval. The cursors start at
+// the latest event already printed and are continued concurrently, their rows are printed in timestamp order
+// as each cursor returns them
+func followProgressSeparately(cmd *cobra.Command, flags *eventsCmdFlags, queryVals eventsQueryValues, dedup *eventDeduplicator, printedRows []EventsRow) error {
+	queryVals = followFromLatest(queryVals, printedRows)
+	lifecycleVals, progressVals := queryVals, queryVals
+	lifecycleVals.Events, progressVals.Events = splitProgressEvents(queryVals.Events)
+
+	cursors := make([]*progressFollowCursor, 0, 2)
+	rows := make([]EventsRow, 0)
+	for _, start := range []struct {
+		description string
+		queryVals   eventsQueryValues
+		backoff     *followBackoff
+	}{
+		{"lifecycle events", lifecycleVals, flags.newFollowBackoff()},
+		{"progress events", progressVals, &followBackoff{base: flags.progressPeriod}},
+	} {
+		data_set, initialRows, err := startOptimizerFollowCursor(start.queryVals)
+		if err != nil {
+			return fmt.Errorf("%v startOptimizerFollowCursor: %w", start.description, err)
+		}
+		if data_set == nil {
+			log.Warnf("Query of %v returned no follow cursor, they will not be followed", start.description)
+			continue
+		}
+		cursors = append(cursors, &progressFollowCursor{description: start.description, data_set: data_set, backoff: start.backoff})
+		rows = append(rows, initialRows...)
+	}
+	sortByTimestamp(rows)
+	if printFollowedRows(cmd, rows, dedup, flags) {
+		flags.stop.printStopped(cmd)
+		return nil
+	}
+	if len(cursors) < 1 {
+		return nil
+	}
+
+	// setup async channels, each cursor being continued in the background in turn with its result, so that the
+	// rows are printed by this loop only
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	resultChan := make(chan *progressFollowResult, len(cursors))
+	for _, cursor := range cursors {
+		resultChan <- &progressFollowResult{cursor: cursor, followEventResult: &followEventResult{data_set: cursor.data_set}}
+	}
+	deadline := flags.followDeadline()
+	reconnects := flags.newFollowReconnects()
+
+	for {
+		select {
+		case <-signals:
+			// exit requested
+			return nil
+		case <-deadline:
+			flags.printFollowStopped(cmd)
+			return nil
+		case result := <-resultChan:
+			reconnectDelay, err := reconnects.check(result.err)
+			if err != nil {
+				return fmt.Errorf("%v: %w", result.cursor.description, err)
+			}
+			if result.err == nil {
+				sortByTimestamp(result.rows)
+				if printFollowedRows(cmd, result.rows, dedup, flags) {
+					flags.stop.printStopped(cmd)
+					return nil
+				}
+				result.cursor.data_set = result.data_set
+			}
+			go func(result *progressFollowResult) {
+				// waiting only once the cursor is exhausted, as for a single cursor
+				if result.err != nil {
+					time.Sleep(reconnectDelay)
+				} else if result.cursorExhausted {
+					result.cursor.backoff.sleep()
+				} else {
+					result.cursor.backoff.reset()
+				}
+				resultChan <- &progressFollowResult{cursor: result.cursor, followEventResult: followDataset(result.cursor.data_set)}
+			}(result)
+		}
+	}
+}
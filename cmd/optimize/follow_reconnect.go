@@ -0,0 +1,64 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/apex/log"
+
+	"github.com/cisco-open/fsoc/cmd/uql"
+)
+
+// followReconnects bounds the consecutive reconnections of a follow session after transient failures of its follow
+// requests, see uql.IsTransient. A nil value allows none
+type followReconnects struct {
+	max       int
+	remaining int
+
+	// backoff spaces the reconnections like exhausted cursors, from --follow-interval up to --follow-max-interval
+	backoff *followBackoff
+}
+
+func (flags *eventsFlags) newFollowReconnects() *followReconnects {
+	return &followReconnects{max: flags.followReconnects, remaining: flags.followReconnects, backoff: flags.newFollowBackoff()}
+}
+
+// check returns the wait before the next follow request after one ending with err, or the error ending following.
+// Success restores the budget and the wait is 0, transient failures use the budget up, each waiting twice as long as
+// the previous one, while other failures, such as rejected credentials or an invalid cursor, end following at once
+func (r *followReconnects) check(err error) (time.Duration, error) {
+	if err == nil {
+		if r != nil {
+			r.remaining = r.max
+			r.backoff.reset()
+		}
+		return 0, nil
+	}
+	if r == nil || !uql.IsTransient(err) {
+		return 0, err
+	}
+	if r.remaining < 1 {
+		if r.max < 1 {
+			return 0, err
+		}
+		return 0, fmt.Errorf("following failed after %v reconnects: %w", r.max, err)
+	}
+	r.remaining--
+	delay := r.backoff.next()
+	log.Warnf("Follow request failed, reconnecting in %v (%v reconnects left): %v", delay, r.remaining, err)
+	return delay, nil
+}
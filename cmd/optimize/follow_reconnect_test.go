@@ -0,0 +1,64 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFollowReconnectsCheck(t *testing.T) {
+	transient := io.ErrUnexpectedEOF
+	flags := &eventsFlags{followInterval: time.Second, followMaxInterval: 3 * time.Second, followReconnects: 2}
+
+	reconnects := flags.newFollowReconnects()
+	delay, err := reconnects.check(transient)
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, delay)
+	delay, err = reconnects.check(transient)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, delay)
+	_, err = reconnects.check(transient)
+	assert.ErrorIs(t, err, transient)
+	assert.ErrorContains(t, err, "following failed after 2 reconnects")
+
+	// a successful request restores the budget and the delay
+	reconnects = flags.newFollowReconnects()
+	for i := 0; i < 3; i++ {
+		_, err = reconnects.check(transient)
+		require.NoError(t, err)
+		delay, err = reconnects.check(nil)
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), delay)
+	}
+	delay, err = reconnects.check(transient)
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, delay)
+
+	// failures that are not transient end following at once
+	rejected := errors.New("rejected")
+	_, err = flags.newFollowReconnects().check(rejected)
+	assert.Equal(t, rejected, err)
+
+	// --follow-max-reconnects 0 disables reconnecting, returning the failure as is
+	flags.followReconnects = 0
+	_, err = flags.newFollowReconnects().check(transient)
+	assert.Equal(t, transient, err)
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// readEventRows reads events previously output as JSON, from the file or from the command's input for "-".
+// The input is a sequence of JSON values (a single value for -o json, one per line for NDJSON), each either an
+// {"items": [...], "total": N} payload as output by the events command, including the batches output when
+// following with -o json-compact, or a single EventsRow. Flattened (--flatten) payloads are not supported, and
+// events without a timestamp or an event type are rejected, as they cannot be ordered or filtered
+func readEventRows(cmd *cobra.Command, path string) ([]EventsRow, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events: %w", err)
+	}
+
+	rows := make([]EventsRow, 0)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for index := 1; ; index++ {
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse JSON value %v of %v: %w", index, path, err)
+		}
+		// the line on which the value ends, i.e., the line of the event for NDJSON
+		line := bytes.Count(data[:decoder.InputOffset()], []byte("\n")) + 1
+		var payload struct {
+			Items *[]EventsRow `json:"items"`
+		}
+		if err := json.Unmarshal(value, &payload); err != nil {
+			return nil, fmt.Errorf("JSON value %v of %v is not an events payload or event: %w", index, path, err)
+		}
+		if payload.Items != nil {
+			for item, row := range *payload.Items {
+				if err := checkEventRow(row); err != nil {
+					return nil, fmt.Errorf("item %v of JSON value %v (line %v) of %v %w", item+1, index, line, path, err)
+				}
+			}
+			rows = append(rows, *payload.Items...)
+			continue
+		}
+		var row EventsRow
+		if err := json.Unmarshal(value, &row); err != nil {
+			return nil, fmt.Errorf("JSON value %v of %v is not an events payload or event: %w", index, path, err)
+		}
+		if err := checkEventRow(row); err != nil {
+			return nil, fmt.Errorf("JSON value %v (line %v) of %v %w", index, line, path, err)
+		}
+		rows = append(rows, row)
+	}
+	for i := range rows {
+		if rows[i].EventAttributes == nil {
+			rows[i].EventAttributes = map[string]any{}
+		}
+		normalizeNumericAttributes(rows[i].EventAttributes)
+	}
+	return rows, nil
+}
+
+// checkEventRow returns an error describing what the row lacks, if its timestamp or event type
+func checkEventRow(row EventsRow) error {
+	if row.Timestamp.IsZero() {
+		return errors.New("is an event without a timestamp")
+	}
+	if eventType, _ := row.EventAttributes["appd.event.type"].(string); eventType == "" {
+		return errors.New("is an event without an appd.event.type attribute")
+	}
+	return nil
+}
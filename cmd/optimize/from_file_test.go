@@ -0,0 +1,47 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadEventRows(t *testing.T) {
+	tests := []struct {
+		input    string
+		rows     int
+		expected string
+	}{
+		{input: `{"items": [{"Timestamp": "2023-08-01T10:00:00Z", "EventAttributes": {"appd.event.type": "stage_started"}}], "total": 1}`, rows: 1},
+		{input: "{\"Timestamp\": \"2023-08-01T10:00:00Z\", \"EventAttributes\": {\"appd.event.type\": \"stage_started\"}}\n{\"EventAttributes\": {\"appd.event.type\": \"stage_ended\"}}\n", expected: "JSON value 2 (line 2) of - is an event without a timestamp"},
+		{input: `{"items": [{"Timestamp": "2023-08-01T10:00:00Z", "EventAttributes": {"appd.event.type": "stage_started"}}, {"Timestamp": "2023-08-01T10:00:00Z"}]}`, expected: "item 2 of JSON value 1 (line 1) of - is an event without an appd.event.type attribute"},
+	}
+	for _, test := range tests {
+		cmd := &cobra.Command{}
+		cmd.SetIn(strings.NewReader(test.input))
+		rows, err := readEventRows(cmd, "-")
+		if test.expected != "" {
+			assert.EqualError(t, err, test.expected)
+			continue
+		}
+		require.NoError(t, err)
+		assert.Len(t, rows, test.rows)
+	}
+}
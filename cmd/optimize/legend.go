@@ -0,0 +1,121 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/cmdkit/term"
+	"github.com/cisco-open/fsoc/output"
+)
+
+// eventCategory groups event types by their meaning for the --legend
+type eventCategory struct {
+	symbol string
+	color  string // ANSI escape sequence
+}
+
+var (
+	categoryStarted        = eventCategory{symbol: "▶", color: "\033[34m"}
+	categoryEnded          = eventCategory{symbol: "■", color: "\033[32m"}
+	categoryProgress       = eventCategory{symbol: "…", color: "\033[37m"}
+	categoryInformational  = eventCategory{symbol: "i", color: "\033[36m"}
+	categoryRecommendation = eventCategory{symbol: "★", color: "\033[33m"}
+	categoryInvalidated    = eventCategory{symbol: "✗", color: "\033[31m"}
+)
+
+const colorReset = "\033[0m"
+
+type eventTypeDescription struct {
+	category    eventCategory
+	description string
+}
+
+// eventTypeDescriptions describe the known defaultEvents and progressEvents
+var eventTypeDescriptions = map[string]eventTypeDescription{
+	"optimization_baselined":           {categoryInformational, "The baseline settings of the workload were recorded"},
+	"optimization_started":             {categoryStarted, "The optimization started, reporting any blockers"},
+	"optimization_ended":               {categoryEnded, "The optimization ended"},
+	"optimization_progress":            {categoryProgress, "Progress of the optimization"},
+	"stage_started":                    {categoryStarted, "An optimization stage started"},
+	"stage_ended":                      {categoryEnded, "An optimization stage ended"},
+	"stage_progress":                   {categoryProgress, "Progress of an optimization stage"},
+	"experiment_started":               {categoryStarted, "An experiment with new settings started"},
+	"experiment_ended":                 {categoryEnded, "An experiment ended"},
+	"experiment_progress":              {categoryProgress, "Progress of an experiment"},
+	"experiment_deployment_started":    {categoryStarted, "The experiment settings are being deployed to the workload"},
+	"experiment_deployment_completed":  {categoryEnded, "The experiment settings were deployed to the workload"},
+	"experiment_measurement_started":   {categoryStarted, "Measurement of the workload under the experiment settings started"},
+	"experiment_measurement_completed": {categoryEnded, "Measurement of the workload under the experiment settings completed"},
+	"experiment_described":             {categoryInformational, "The settings and results of an experiment were described"},
+	"recommendation_identified":        {categoryRecommendation, "Settings were identified as a candidate recommendation"},
+	"recommendation_verified":          {categoryRecommendation, "A recommendation was verified and can be applied"},
+	"recommendation_invalidated":       {categoryInvalidated, "A recommendation was found invalid and should not be applied"},
+}
+
+// printEventLegend outputs, ahead of human output, the symbol and description of each event type present in the rows,
+// in the order of defaultEvents and progressEvents. Symbols are colored when the output is a color terminal
+func printEventLegend(cmd *cobra.Command, rows []EventsRow) {
+	if format, _ := cmd.Flags().GetString("output"); format == "json" || format == "json-compact" || format == "yaml" {
+		return
+	}
+	present := make(map[string]bool)
+	for _, row := range rows {
+		present[eventType(row)] = true
+	}
+	if len(present) < 1 {
+		return
+	}
+	order := make(map[string]int)
+	for i, event := range append(append([]string{}, defaultEvents...), progressEvents...) {
+		order[event] = i
+	}
+	eventTypes := make([]string, 0, len(present))
+	for event := range present {
+		eventTypes = append(eventTypes, event)
+	}
+	sort.Slice(eventTypes, func(i, j int) bool {
+		left, leftKnown := order[eventTypes[i]]
+		right, rightKnown := order[eventTypes[j]]
+		if leftKnown != rightKnown {
+			return leftKnown
+		}
+		if leftKnown {
+			return left < right
+		}
+		return eventTypes[i] < eventTypes[j]
+	})
+
+	colored := term.AllowsColorOutput(cmd.OutOrStdout())
+	var sb strings.Builder
+	sb.WriteString("Legend:\n")
+	for _, event := range eventTypes {
+		description, ok := eventTypeDescriptions[event]
+		if !ok {
+			description = eventTypeDescription{eventCategory{symbol: "?"}, "Event type unknown to this version of fsoc"}
+		}
+		symbol := description.category.symbol
+		if colored && description.category.color != "" {
+			symbol = description.category.color + symbol + colorReset
+		}
+		fmt.Fprintf(&sb, "  %v %-34v %v\n", symbol, event, description.description)
+	}
+	sb.WriteString("\n")
+	output.PrintCmdStatus(cmd, sb.String())
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/output"
+)
+
+func init() {
+	// TODO move this logic to optimize root when implementing unit tests
+	optimizeCmd.AddCommand(NewCmdListOptimizations())
+}
+
+func NewCmdListOptimizations() *cobra.Command {
+	var flags eventsFlags
+	command := &cobra.Command{
+		Use:   "list-optimizations",
+		Short: "List the optimizer IDs of optimizations matching the given workload criteria",
+		Long: `
+List the optimizer IDs of optimizations matching the given workload criteria
+
+If no flags are provided, all optimizations reported within the time interval will be listed
+You can optionally filter optimizations by cluster ID, namespace and/or workload name
+`,
+		Example: `  fsoc optimize list-optimizations
+  fsoc optimize list-optimizations --namespace some-namespace --since -1d
+  fsoc optimize list-optimizations --workload-name some-workload --cluster-id 00000000-0000-0000-0000-000000000000`,
+		Args:             cobra.NoArgs,
+		RunE:             listOptimizationsCmd(&flags),
+		TraverseChildren: true,
+		Annotations: map[string]string{
+			output.TableFieldsAnnotation:  "OptimizerId: .OptimizerId, Namespace: .Namespace, WorkloadName: .WorkloadName",
+			output.DetailFieldsAnnotation: "OptimizerId: .OptimizerId, ClusterId: .ClusterId, Namespace: .Namespace, WorkloadName: .WorkloadName",
+		},
+	}
+
+	command.Flags().StringVarP(&flags.clusterId, "cluster-id", "c", "", "List optimizations constrained to a specific cluster by its ID")
+	command.Flags().StringVarP(&flags.namespace, "namespace", "n", "", "List optimizations constrained to a specific namespace by its name")
+	command.Flags().StringVarP(&flags.workloadName, "workload-name", "w", "", "List optimizations constrained to a specific workload by its name")
+	command.Flags().StringVarP(&flags.entityFilter, "entity-filter", "", "", "List optimizations matching a UQL predicate on the optimization entities, combined with the other filters by AND, e.g., \"attributes(k8s.cluster.name) = 'prod'\"")
+
+	command.Flags().StringVarP(&flags.since, "since", "s", "", "List optimizations reported in the time interval starting at a relative or exact time. (default: -1h)")
+	command.Flags().StringVarP(&flags.until, "until", "u", "", "List optimizations reported in the time interval ending at a relative or exact time. (default: now)")
+
+	command.Flags().StringVarP(&flags.solutionName, "solution-name", "", "optimize", "Intended for developer usage, overrides the name of the solution defining the FMM types for reading")
+	if err := command.LocalFlags().MarkHidden("solution-name"); err != nil {
+		log.Warnf("Failed to set list-optimizations solution-name flag hidden: %v", err)
+	}
+	command.Flags().BoolVarP(&flags.debugTiming, "debug-timing", "", false, "Print a summary of UQL query timings to stderr on completion")
+	addStrictFlag(command)
+
+	return command
+}
+
+func listOptimizationsCmd(flags *eventsFlags) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		flags.retries = newRetryBudget(cmd)
+		responseWarnings.setStrict(cmd)
+		if err := flags.checkEntityFilter(cmd); err != nil {
+			return err
+		}
+		if flags.debugTiming {
+			defer startDebugTiming(cmd)()
+		}
+
+		optimizationRows, err := listOptimizationRows(flags)
+		if err != nil {
+			return fmt.Errorf("listOptimizationRows: %w", err)
+		}
+
+		output.PrintCmdOutput(cmd, struct {
+			Items []optimizationRow `json:"items"`
+			Total int               `json:"total"`
+		}{Items: optimizationRows, Total: len(optimizationRows)})
+
+		return nil
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"os"
+
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/json"
+	"github.com/apex/log/handlers/level"
+	"github.com/apex/log/handlers/multi"
+	"github.com/spf13/cobra"
+)
+
+// optimizeLogFile receives the warning and error log records of the optimize commands when --log-file is set,
+// consoleLogHandler is the handler it was teed from, restored when the file is closed
+var (
+	optimizeLogFile   *os.File
+	consoleLogHandler log.Handler
+)
+
+func init() {
+	optimizeCmd.PersistentFlags().String("log-file", "", "Append warning and error log records as JSON lines to the given file, in addition to the console output")
+	optimizeCmd.PersistentPreRun = optimizePreRun
+	optimizeCmd.PersistentPostRun = optimizePostRun
+}
+
+// optimizePreRun runs the root command's pre-run hook, which cobra would otherwise skip in favor of this one,
+// and then tees the log records to the --log-file, if given
+func optimizePreRun(cmd *cobra.Command, args []string) {
+	if root := cmd.Root(); root.PersistentPreRun != nil {
+		root.PersistentPreRun(cmd, args)
+	}
+
+	path, _ := cmd.Flags().GetString("log-file")
+	if path == "" {
+		return
+	}
+	logger, ok := log.Log.(*log.Logger)
+	if !ok {
+		log.Warnf("Logging to %q is not supported by the configured logger", path)
+		return
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		log.Warnf("Failed to open log file %q: %v", path, err)
+		return
+	}
+	optimizeLogFile = file
+	consoleLogHandler = logger.Handler
+	log.SetHandler(multi.New(logger.Handler, level.New(json.New(file), log.WarnLevel)))
+}
+
+// optimizePostRun flushes and closes the --log-file, if any, and runs the root command's post-run hook.
+// The follow loops return normally when interrupted, so this also runs on the interrupt path
+func optimizePostRun(cmd *cobra.Command, args []string) {
+	if optimizeLogFile != nil {
+		log.SetHandler(consoleLogHandler)
+		if err := optimizeLogFile.Sync(); err != nil {
+			log.Warnf("Failed to flush log file %q: %v", optimizeLogFile.Name(), err)
+		}
+		if err := optimizeLogFile.Close(); err != nil {
+			log.Warnf("Failed to close log file %q: %v", optimizeLogFile.Name(), err)
+		}
+		optimizeLogFile = nil
+	}
+
+	if root := cmd.Root(); root.PersistentPostRun != nil {
+		root.PersistentPostRun(cmd, args)
+	}
+}
@@ -23,11 +23,15 @@ var optimizeCmd = &cobra.Command{
 	Use:   "optimize",
 	Short: "Perform optimize interactions",
 	Long: `Interact with optimize components. Currently only workload profile reports are available 
-via the report subcommand.`,
+via the report subcommand.
+
+Commands exit with status 3 when the platform rejects the credentials, e.g., once the access token expired.`,
 	Example:          `  fsoc optimize report "frontend"`,
 	TraverseChildren: true,
 }
 
 func NewSubCmd() *cobra.Command {
+	applySolutionName(optimizeCmd)
+	surfaceAuthErrors(optimizeCmd)
 	return optimizeCmd
 }
@@ -0,0 +1,84 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/output"
+)
+
+// optimizerIdPattern matches optimizer IDs of the form namespace-name-uuid, the namespace and name being separated
+// by hyphens which they may contain themselves
+var optimizerIdPattern = regexp.MustCompile(`^(.+)-([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})$`)
+
+// optimizerIdColumns are the fields set by normalizeOptimizerIds, added to the human output after the OptimizerId
+const optimizerIdColumns = "OptNamespace: .OptNamespace, OptName: .OptName, OptUUID: .OptUUID"
+
+// splitOptimizerId splits the optimizer ID into the namespace and name of its workload and its UUID. As both the
+// namespace and name may contain hyphens, the namespace is taken from the namespace attribute when the ID starts with
+// it, from the first hyphen otherwise. ok is false if the ID doesn't end with a UUID
+func splitOptimizerId(optimizerId string, namespaceHint string) (namespace string, name string, uuid string, ok bool) {
+	match := optimizerIdPattern.FindStringSubmatch(optimizerId)
+	if match == nil {
+		return "", "", "", false
+	}
+	prefix := match[1]
+	if namespaceHint != "" && strings.HasPrefix(prefix, namespaceHint+"-") {
+		return namespaceHint, strings.TrimPrefix(prefix, namespaceHint+"-"), match[2], true
+	}
+	namespace, name, found := strings.Cut(prefix, "-")
+	if !found {
+		return "", "", "", false
+	}
+	return namespace, name, match[2], true
+}
+
+// normalizeOptimizerIds sets the optimizer ID components of each row. Rows whose optimizer ID can't be split keep
+// empty components. It must be applied before aliasing, which may rename the attributes it reads
+func normalizeOptimizerIds(rows []EventsRow) {
+	for i := range rows {
+		optimizerId, _ := rows[i].EventAttributes["optimize.optimization.optimizer_id"].(string)
+		namespaceHint, _ := rows[i].EventAttributes["k8s.namespace.name"].(string)
+		namespace, name, uuid, ok := splitOptimizerId(optimizerId, namespaceHint)
+		if !ok {
+			log.WithField("optimizerId", optimizerId).Debug("Optimizer ID is not of the form namespace-name-uuid, leaving its components empty")
+			continue
+		}
+		rows[i].OptNamespace, rows[i].OptName, rows[i].OptUUID = namespace, name, uuid
+	}
+}
+
+// addOptimizerIdColumns adds the optimizer ID components to the command's human output, following the OptimizerId
+// column, or last if the command has none
+func addOptimizerIdColumns(cmd *cobra.Command) {
+	for _, name := range []string{output.TableFieldsAnnotation, output.DetailFieldsAnnotation} {
+		spec, ok := cmd.Annotations[name]
+		if !ok {
+			continue
+		}
+		column := `OptimizerId: .EventAttributes["optimize.optimization.optimizer_id"]`
+		if index := strings.Index(spec, column); index >= 0 {
+			end := index + len(column)
+			cmd.Annotations[name] = spec[:end] + ", " + optimizerIdColumns + spec[end:]
+		} else {
+			cmd.Annotations[name] = spec + ", " + optimizerIdColumns
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// readOptimizerIdFile reads the optimizer IDs listed one per line in the file, trimming whitespace and skipping
+// blank lines and lines starting with #. Duplicates are listed once. The file must list at least one ID
+func readOptimizerIdFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open optimizer ID file: %w", err)
+	}
+	defer file.Close()
+
+	ids := make([]string, 0)
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		id := strings.TrimSpace(scanner.Text())
+		if id == "" || strings.HasPrefix(id, "#") || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read optimizer ID file %q: %w", path, err)
+	}
+	if len(ids) < 1 {
+		return nil, fmt.Errorf("optimizer ID file %q lists no optimizer IDs", path)
+	}
+	return ids, nil
+}
+
+// listedOptimizerIds returns the optimizer IDs of --optimizer-id-file, along with the --optimizer-id if also given,
+// or nil without --optimizer-id-file
+func (flags *eventsFlags) listedOptimizerIds() ([]string, error) {
+	if flags.optimizerIdFile == "" {
+		return nil, nil
+	}
+	ids, err := readOptimizerIdFile(flags.optimizerIdFile)
+	if err != nil {
+		return nil, err
+	}
+	if flags.optimizerId != "" && !slices.Contains(ids, flags.optimizerId) {
+		ids = append([]string{flags.optimizerId}, ids...)
+	}
+	return ids, nil
+}
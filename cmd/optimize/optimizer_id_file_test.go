@@ -0,0 +1,55 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOptimizerIdFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		ids      []string
+		expected string
+	}{
+		{name: "one per line", content: "ns-a-1\nns-b-2\n", ids: []string{"ns-a-1", "ns-b-2"}},
+		{name: "comments blanks and whitespace", content: "# checkout\n\n  ns-a-1  \r\n\t# ns-x-9\nns-b-2", ids: []string{"ns-a-1", "ns-b-2"}},
+		{name: "duplicates", content: "ns-a-1\nns-b-2\nns-a-1\n", ids: []string{"ns-a-1", "ns-b-2"}},
+		{name: "no IDs", content: "# none yet\n\n", expected: "lists no optimizer IDs"},
+		{name: "empty", content: "", expected: "lists no optimizer IDs"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "optimizers.txt")
+			require.NoError(t, os.WriteFile(path, []byte(test.content), 0644))
+			ids, err := readOptimizerIdFile(path)
+			if test.expected != "" {
+				assert.ErrorContains(t, err, test.expected)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.ids, ids)
+		})
+	}
+
+	_, err := readOptimizerIdFile(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.ErrorContains(t, err, "failed to open optimizer ID file")
+}
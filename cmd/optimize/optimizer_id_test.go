@@ -0,0 +1,51 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitOptimizerId(t *testing.T) {
+	const uuid = "0a1b2c3d-0000-4000-8000-00000000abcd"
+	tests := []struct {
+		optimizerId   string
+		namespaceHint string
+		namespace     string
+		name          string
+		ok            bool
+	}{
+		{optimizerId: "checkout-cart-" + uuid, namespace: "checkout", name: "cart", ok: true},
+		{optimizerId: "checkout-cart-api-" + uuid, namespace: "checkout", name: "cart-api", ok: true},
+		{optimizerId: "team-a-cart-api-" + uuid, namespaceHint: "team-a", namespace: "team-a", name: "cart-api", ok: true},
+		{optimizerId: "team-a-cart-" + uuid, namespaceHint: "other", namespace: "team", name: "a-cart", ok: true},
+		{optimizerId: "checkout-cart-0A1B2C3D-0000-4000-8000-00000000ABCD", namespace: "checkout", name: "cart", ok: true},
+		{optimizerId: "cart-" + uuid},
+		{optimizerId: "checkout-cart-0a1b2c3d"},
+		{optimizerId: "checkout-cart-" + uuid + "-x"},
+		{optimizerId: ""},
+	}
+	for _, test := range tests {
+		namespace, name, actualUuid, ok := splitOptimizerId(test.optimizerId, test.namespaceHint)
+		assert.Equal(t, test.ok, ok, test.optimizerId)
+		if test.ok {
+			assert.Equal(t, test.namespace, namespace, test.optimizerId)
+			assert.Equal(t, test.name, name, test.optimizerId)
+			assert.Equal(t, test.optimizerId[len(test.optimizerId)-len(uuid):], actualUuid, test.optimizerId)
+		}
+	}
+}
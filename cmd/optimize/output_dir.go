@@ -0,0 +1,157 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/output"
+)
+
+// unknownOptimizerFileName is the base name of the file collecting events that carry no optimizer ID
+const unknownOptimizerFileName = "unknown-optimizer"
+
+// optimizerRows holds the events of a single optimizer, in their original order
+type optimizerRows struct {
+	OptimizerId string
+	Rows        []EventsRow
+}
+
+// optimizerFileRow describes a file written by writeRowsByOptimizer
+type optimizerFileRow struct {
+	OptimizerId string
+	File        string
+	Events      int
+}
+
+// groupRowsByOptimizer splits the rows by their optimizer ID attribute, keeping the groups in the order in which
+// each optimizer first appears. Rows with no optimizer ID are grouped under an empty ID
+func groupRowsByOptimizer(rows []EventsRow) []optimizerRows {
+	groups := make([]optimizerRows, 0)
+	index := make(map[string]int)
+	for _, row := range rows {
+		optimizerId, _ := row.EventAttributes["optimize.optimization.optimizer_id"].(string)
+		position, ok := index[optimizerId]
+		if !ok {
+			position = len(groups)
+			index[optimizerId] = position
+			groups = append(groups, optimizerRows{OptimizerId: optimizerId})
+		}
+		groups[position].Rows = append(groups[position].Rows, row)
+	}
+	return groups
+}
+
+// outputFileExtension returns the file name extension matching the selected output format
+func outputFileExtension(format string) string {
+	switch format {
+	case "json", "json-compact":
+		return ".json"
+	case "yaml":
+		return ".yaml"
+	default:
+		return ".txt"
+	}
+}
+
+// optimizerFileName derives a file name from the optimizer ID, replacing characters that are not safe in file names
+func optimizerFileName(optimizerId string, extension string) string {
+	if optimizerId == "" {
+		return unknownOptimizerFileName + extension
+	}
+	name := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, optimizerId)
+	if name == "." || name == ".." {
+		name = strings.Repeat("_", len(name))
+	}
+	return name + extension
+}
+
+// uniqueFileName returns the name, suffixed with a counter before its extension when an earlier file of the same
+// directory was already given that name, e.g., when distinct optimizer IDs are sanitized to the same file name.
+// Names are compared ignoring case, as file systems may do
+func uniqueFileName(name string, extension string, used map[string]bool) string {
+	base := strings.TrimSuffix(name, extension)
+	unique := name
+	for i := 2; used[strings.ToLower(unique)]; i++ {
+		unique = fmt.Sprintf("%v-%v%v", base, i, extension)
+	}
+	used[strings.ToLower(unique)] = true
+	return unique
+}
+
+// writeRowsByOptimizer writes each group of rows to its own file in dir, in the selected output format, creating
+// dir if missing. The query warnings apply to all of the rows, so each file includes them. A summary of the files
+// written is then printed
+func writeRowsByOptimizer(cmd *cobra.Command, dir string, groups []optimizerRows, flatten bool) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("os.MkdirAll: %w", err)
+	}
+	format, _ := cmd.Flags().GetString("output")
+	extension := outputFileExtension(format)
+
+	written := make([]optimizerFileRow, 0, len(groups))
+	lines := make([][]string, 0, len(groups))
+	used := make(map[string]bool, len(groups))
+	warnings := responseWarnings.drain()
+	for _, group := range groups {
+		path := filepath.Join(dir, uniqueFileName(optimizerFileName(group.OptimizerId, extension), extension, used))
+		if err := writeEventRowsFile(cmd, path, group.Rows, warnings, flatten); err != nil {
+			return fmt.Errorf("failed to write events of optimizer %q to %q: %w", group.OptimizerId, path, err)
+		}
+		written = append(written, optimizerFileRow{OptimizerId: group.OptimizerId, File: path, Events: len(group.Rows)})
+		lines = append(lines, []string{group.OptimizerId, path, strconv.Itoa(len(group.Rows))})
+	}
+
+	if len(written) < 1 {
+		printNoResults(cmd, "No events found, no files written\n")
+		return nil
+	}
+	output.PrintCmdOutputCustom(cmd, struct {
+		Items []optimizerFileRow `json:"items"`
+		Total int                `json:"total"`
+	}{Items: written, Total: len(written)}, &output.Table{
+		Headers: []string{"OptimizerId", "File", "Events"},
+		Lines:   lines,
+	})
+	return nil
+}
+
+// writeEventRowsFile writes the rows to the file at path, formatted as they would be printed to stdout
+func writeEventRowsFile(cmd *cobra.Command, path string, rows []EventsRow, warnings []queryWarning, flatten bool) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("os.Create: %w", err)
+	}
+	defer file.Close()
+
+	previous := cmd.OutOrStdout()
+	cmd.SetOut(file)
+	printEventRows(cmd, rows, warnings, flatten, nil)
+	cmd.SetOut(previous)
+
+	return file.Close()
+}
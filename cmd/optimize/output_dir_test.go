@@ -0,0 +1,59 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cisco-open/fsoc/cmd/uql"
+)
+
+func TestUniqueFileName(t *testing.T) {
+	used := map[string]bool{}
+	names := []string{}
+	for _, optimizerId := range []string{"ns:a", "ns/a", "NS_A", "ns_a-2", "ns_b", ""} {
+		names = append(names, uniqueFileName(optimizerFileName(optimizerId, ".json"), ".json", used))
+	}
+	assert.Equal(t, []string{"ns_a.json", "ns_a-2.json", "NS_A-3.json", "ns_a-2-2.json", "ns_b.json", "unknown-optimizer.json"}, names)
+}
+
+func TestWriteRowsByOptimizerWarnings(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("output", "json-compact", "")
+	cmd.SetOut(&bytes.Buffer{})
+	responseWarnings.add("events query", 2, []*uql.Error{{Title: "Partial data", Detail: "timed out"}})
+
+	dir := t.TempDir()
+	groups := []optimizerRows{
+		{OptimizerId: "ns-a", Rows: []EventsRow{{EventAttributes: map[string]any{}}}},
+		{OptimizerId: "ns-b", Rows: []EventsRow{{EventAttributes: map[string]any{}}}},
+	}
+	require.NoError(t, writeRowsByOptimizer(cmd, dir, groups, false))
+
+	// each file reports the warnings of the query the rows came from
+	for _, name := range []string{"ns-a.json", "ns-b.json"} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), `"warnings":[{"query":"events query","page":2,"title":"Partial data","detail":"timed out"}]`, name)
+	}
+	assert.Empty(t, responseWarnings.drain())
+}
@@ -0,0 +1,93 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/cmd/uql"
+	"github.com/cisco-open/fsoc/cmdkit/term"
+)
+
+// defaultReviewPageSize is the number of events per page of --paged-review unless --page-size is given
+const defaultReviewPageSize = 25
+
+// reviewPages prints the events newest first, one page at a time, prompting before retrieving each older page
+// until the user quits or the start of the time interval is reached
+func (flags *eventsCmdFlags) reviewPages(cmd *cobra.Command, queryVals eventsQueryValues) error {
+	in := cmd.InOrStdin()
+	if !term.IsTerminal(in) {
+		return errors.New("--paged-review requires a terminal to prompt for the next page")
+	}
+	if flags.pageSize == -1 {
+		queryVals.Limits = defaultReviewPageSize
+	}
+	queryVals.Desc = true
+
+	resp, err := uql.ClientV1.ExecuteQuery(eventsQuery(queryVals))
+	if err != nil {
+		return fmt.Errorf("uql.ClientV1.ExecuteQuery: %w", err)
+	}
+	if err := responseWarnings.checkResponse(resp, "Execution", "events query"); err != nil {
+		return err
+	}
+	if main_data_set := resp.Main(); main_data_set == nil || len(main_data_set.Data) < 1 {
+		return flags.noResults(cmd, "No event results found for given input\n")
+	}
+
+	reader := bufio.NewReader(in)
+	reviewed := 0
+	pages := uql.Pages{Nested: true, Continue: flags.retries.continueQuery, Description: "events query", OnErrors: responseWarnings.onPageErrors("events query")}
+	_, err = pages.Iterate(resp, func(page int, pageDataSet *uql.DataSet) (bool, error) {
+		rows, err := extractEventsData(pageDataSet)
+		if err != nil {
+			return false, fmt.Errorf("page %v extractEventsData: %w", page, err)
+		}
+		reviewed += len(rows)
+		rows = flags.filterByProgress(flags.filterByAttributePresence(rows))
+		if flags.normalizeIds {
+			normalizeOptimizerIds(rows)
+		}
+		flags.presentNumericAttributes(rows)
+		flags.redactions.apply(rows)
+		flags.aliasMap.apply(rows)
+		if len(rows) > 0 {
+			cmd.PrintErrf("Page %v: events from %v back to %v\n", page, rows[0].Timestamp.Format(time.RFC3339), rows[len(rows)-1].Timestamp.Format(time.RFC3339))
+		} else {
+			cmd.PrintErrf("Page %v: no events pass the filters\n", page)
+		}
+		printEventRows(cmd, rows, responseWarnings.drain(), flags.flatten, nil)
+
+		if _, ok := pageDataSet.Links["next"]; !ok {
+			cmd.PrintErrf("Reached the start of the time interval after %v events\n", reviewed)
+			return false, nil
+		}
+		cmd.PrintErr("Press Enter for older events, or q to quit: ")
+		answer, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return false, fmt.Errorf("failed to read answer: %w", err)
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		return answer != "q" && answer != "quit" && !errors.Is(err, io.EOF), nil
+	})
+	return err
+}
@@ -0,0 +1,166 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/cmdkit/term"
+)
+
+// checkInteractive verifies that --interactive can be honored: an optimizer ID filter must be resolved from the
+// other filters and the input must be a terminal to prompt on
+func (flags *eventsFlags) checkInteractive(cmd *cobra.Command) error {
+	if !flags.interactive {
+		return nil
+	}
+	if flags.namespace == "" && flags.workloadName == "" && flags.optimizerIdPrefix == "" && flags.entityFilter == "" {
+		return errors.New("--interactive requires --namespace, --workload-name, --optimizer-id-prefix or --entity-filter filters to select optimizers from")
+	}
+	if !term.IsTerminal(cmd.InOrStdin()) {
+		return errors.New("--interactive requires a terminal to prompt for the optimizers to select")
+	}
+	return nil
+}
+
+// optimizerResolver returns the IDs of the optimizers matching the namespace, workload name, optimizer ID prefix and
+// entity filters, see resolveOptimizers. Tests replace it to build queries without a backend
+type optimizerResolver func() ([]string, error)
+
+// errNoOptimizations is returned by filteredOptimizerIds when no optimization matches the filters
+var errNoOptimizations = errors.New("no optimization entities found matching the given criteria")
+
+// commandResolver returns the resolver of the command, prompting on its terminal with --interactive
+func (flags *eventsFlags) commandResolver(cmd *cobra.Command) optimizerResolver {
+	return func() ([]string, error) {
+		return flags.resolveOptimizers(cmd)
+	}
+}
+
+// filteredOptimizerIds returns the optimizer IDs the queries are constrained to: those of --optimizer-id-file, or
+// else those returned by the resolver for the namespace, workload name, optimizer ID prefix and entity filters.
+// It returns nil when the optimizers are constrained by --optimizer-id alone, or not at all. The resolver is called
+// once per invocation, so that the entity query runs and the user is prompted only once however many queries of
+// the command filter on the optimizer IDs
+func (flags *eventsFlags) filteredOptimizerIds() ([]string, error) {
+	ids, err := flags.listedOptimizerIds()
+	if err != nil || ids != nil || flags.optimizerId != "" {
+		return ids, err
+	}
+	if flags.namespace == "" && flags.workloadName == "" && flags.optimizerIdPrefix == "" && flags.entityFilter == "" {
+		return nil, nil
+	}
+	if flags.resolvedIds != nil {
+		return flags.resolvedIds, nil
+	}
+	if flags.resolver == nil {
+		return nil, errors.New("the optimizer IDs matching the filters cannot be resolved without a resolver")
+	}
+	if ids, err = flags.resolver(); err != nil {
+		return nil, fmt.Errorf("resolveOptimizers: %w", err)
+	}
+	if len(ids) < 1 {
+		return nil, errNoOptimizations
+	}
+	flags.resolvedIds = ids
+	return ids, nil
+}
+
+// resolveOptimizers returns the optimizer IDs matching the filters. With --interactive and more than one match,
+// the user picks which of them to keep
+func (flags *eventsFlags) resolveOptimizers(cmd *cobra.Command) ([]string, error) {
+	rows, err := matchingOptimizations(flags)
+	if err != nil {
+		return nil, err
+	}
+	if flags.interactive && len(rows) > 1 {
+		if rows, err = pickOptimizations(cmd, rows); err != nil {
+			return nil, err
+		}
+	}
+	results := make([]string, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, row.OptimizerId)
+	}
+	return results, nil
+}
+
+// pickOptimizations lists the optimizations on stderr and prompts for a selection by number
+func pickOptimizations(cmd *cobra.Command, rows []optimizationRow) ([]optimizationRow, error) {
+	cmd.PrintErrf("%v optimizations match the given criteria:\n", len(rows))
+	for i, row := range rows {
+		cmd.PrintErrf("  %3d) %v/%v  %v\n", i+1, row.Namespace, row.WorkloadName, row.OptimizerId)
+	}
+	cmd.PrintErr("Select optimizations by number, separated by commas, or as ranges such as 2-4 [all]: ")
+	answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read selection: %w", err)
+	}
+	indices, err := parseSelection(strings.TrimSpace(answer), len(rows))
+	if err != nil {
+		return nil, err
+	}
+	if indices == nil {
+		return rows, nil
+	}
+	selected := make([]optimizationRow, 0, len(indices))
+	for _, index := range indices {
+		selected = append(selected, rows[index])
+	}
+	return selected, nil
+}
+
+// parseSelection parses a selection such as "1,3-4" of 1-based numbers up to count and returns the selected
+// 0-based indices, in order and without duplicates. An empty selection or "all" selects everything and returns nil
+func parseSelection(selection string, count int) ([]int, error) {
+	if selection == "" || strings.EqualFold(selection, "all") {
+		return nil, nil
+	}
+	chosen := make([]bool, count)
+	for _, part := range strings.Split(selection, ",") {
+		part = strings.TrimSpace(part)
+		from, to, isRange := strings.Cut(part, "-")
+		first, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return nil, fmt.Errorf("invalid selection %q, expected numbers or ranges such as 2-4", part)
+		}
+		last := first
+		if isRange {
+			if last, err = strconv.Atoi(strings.TrimSpace(to)); err != nil {
+				return nil, fmt.Errorf("invalid selection %q, expected numbers or ranges such as 2-4", part)
+			}
+		}
+		if first < 1 || last > count || first > last {
+			return nil, fmt.Errorf("selection %q is out of range, expected numbers between 1 and %v", part, count)
+		}
+		for i := first; i <= last; i++ {
+			chosen[i-1] = true
+		}
+	}
+	indices := make([]int, 0, count)
+	for i, ok := range chosen {
+		if ok {
+			indices = append(indices, i)
+		}
+	}
+	return indices, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// filterProfilesFile holds the named filter profiles, mapping each profile name to flag names and values, e.g.,
+//
+//	prod-checkout:
+//	  cluster-id: 00000000-0000-0000-0000-000000000000
+//	  namespace: checkout
+//	  since: -1d
+const filterProfilesFile = "~/.fsoc-optimize-profiles.yaml"
+
+// filterProfileFlagGroups are the flags a filter profile may set. A group is skipped as a whole when one of the
+// flags listed for it in filterProfileOverrides is set on the command line, so that profile values don't combine
+// with mutually exclusive explicit flags, e.g., a profile --since with an explicit --window
+var filterProfileFlagGroups = [][]string{
+	{"cluster-id", "namespace", "workload-name"},
+	{"since", "until", "window", "range"},
+}
+
+var filterProfileOverrides = [][]string{
+	{"optimizer-id"},
+	{"since", "until", "window", "range"},
+}
+
+// applyFilterProfile sets the flags supplied by the --filter-profile that were not set on the command line,
+// so that explicit flags take precedence over the profile, which takes precedence over the flag defaults
+func (flags *eventsFlags) applyFilterProfile(cmd *cobra.Command) error {
+	if flags.filterProfile == "" {
+		return nil
+	}
+	profile, err := loadFilterProfile(flags.filterProfile)
+	if err != nil {
+		return err
+	}
+
+	allowed := make(map[string]int)
+	for group, names := range filterProfileFlagGroups {
+		for _, name := range names {
+			allowed[name] = group
+		}
+	}
+	names := make([]string, 0, len(profile))
+	for name := range profile {
+		group, ok := allowed[name]
+		if !ok {
+			return fmt.Errorf("filter profile %q sets unsupported flag %q, expected one of: %v", flags.filterProfile, name, strings.Join(filterProfileFlagNames(), ", "))
+		}
+		if cmd.Flags().Lookup(name) == nil {
+			log.Warnf("Filter profile %q flag %q does not apply to this command, ignoring it", flags.filterProfile, name)
+			continue
+		}
+		if cmd.Flags().Changed(name) || anyFlagChanged(cmd, filterProfileOverrides[group]) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := cmd.Flags().Set(name, profile[name]); err != nil {
+			return fmt.Errorf("filter profile %q flag %q: %w", flags.filterProfile, name, err)
+		}
+		log.WithFields(log.Fields{"profile": flags.filterProfile, "flag": name, "value": profile[name]}).Info("Applied filter profile setting")
+	}
+	return nil
+}
+
+// loadFilterProfile reads the named profile from the filterProfilesFile
+func loadFilterProfile(name string) (map[string]string, error) {
+	home, _ := os.UserHomeDir()
+	path := strings.Replace(filterProfilesFile, "~", home, 1)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("filter profile %q not found, profiles file %v does not exist", name, path)
+		}
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+	profiles := make(map[string]map[string]string)
+	if err := yaml.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles file %v: %w", path, err)
+	}
+	profile, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("filter profile %q not found in %v", name, path)
+	}
+	return profile, nil
+}
+
+func filterProfileFlagNames() []string {
+	names := make([]string, 0)
+	for _, group := range filterProfileFlagGroups {
+		names = append(names, group...)
+	}
+	return names
+}
+
+func anyFlagChanged(cmd *cobra.Command, names []string) bool {
+	for _, name := range names {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyFilterProfile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	profiles := "checkout:\n  cluster-id: profile-cluster\n  namespace: checkout\n  since: -1d\n"
+	require.NoError(t, os.WriteFile(filepath.Join(home, filepath.Base(filterProfilesFile)), []byte(profiles), 0600))
+
+	tests := []struct {
+		name      string
+		args      []string
+		clusterId string
+		namespace string
+		since     string
+		window    string
+	}{
+		{name: "built-in defaults", clusterId: "", namespace: "", since: ""},
+		{name: "profile values", args: []string{"--filter-profile", "checkout"}, clusterId: "profile-cluster", namespace: "checkout", since: "-1d"},
+		{name: "explicit flag", args: []string{"--filter-profile", "checkout", "--namespace", "payments", "--since", "-2h"}, clusterId: "profile-cluster", namespace: "payments", since: "-2h"},
+		{name: "explicit exclusive flag", args: []string{"--filter-profile", "checkout", "--window", "today"}, clusterId: "profile-cluster", namespace: "checkout", since: "", window: "today"},
+		{name: "explicit optimizer", args: []string{"--filter-profile", "checkout", "--optimizer-id", "ns-name-1"}, clusterId: "", namespace: "", since: "-1d"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flags := &eventsCmdFlags{}
+			command := newCmdEvents(flags)
+			require.NoError(t, command.ParseFlags(test.args))
+			require.NoError(t, flags.applyFilterProfile(command))
+			assert.Equal(t, test.clusterId, flags.clusterId)
+			assert.Equal(t, test.namespace, flags.namespace)
+			assert.Equal(t, test.since, flags.since)
+			assert.Equal(t, test.window, flags.window)
+		})
+	}
+
+	flags := &eventsCmdFlags{}
+	command := newCmdEvents(flags)
+	require.NoError(t, command.ParseFlags([]string{"--filter-profile", "missing"}))
+	assert.ErrorContains(t, flags.applyFilterProfile(command), `filter profile "missing" not found`)
+}
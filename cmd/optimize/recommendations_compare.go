@@ -0,0 +1,145 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/cmdkit/term"
+	"github.com/cisco-open/fsoc/output"
+)
+
+// recommendationFieldDiff is a field of the latest recommendations of the two compared optimizers
+type recommendationFieldDiff struct {
+	Field    string `json:"field" yaml:"field"`
+	Primary  string `json:"primary" yaml:"primary"`
+	Compared string `json:"compared" yaml:"compared"`
+	Differs  bool   `json:"differs" yaml:"differs"`
+}
+
+type recommendationDiff struct {
+	PrimaryOptimizerId  string                    `json:"primaryOptimizerId" yaml:"primaryOptimizerId"`
+	ComparedOptimizerId string                    `json:"comparedOptimizerId" yaml:"comparedOptimizerId"`
+	Items               []recommendationFieldDiff `json:"items"`
+	Total               int                       `json:"total"`
+}
+
+// checkCompareOptimizer validates --compare-optimizer, which compares with the optimizer given by --optimizer-id
+func (flags *recommendationsCmdFlags) checkCompareOptimizer() error {
+	if flags.compareOptimizer == "" {
+		return nil
+	}
+	if flags.optimizerId == "" {
+		return errors.New("--compare-optimizer requires --optimizer-id to compare with")
+	}
+	if flags.compareOptimizer == flags.optimizerId {
+		return errors.New("--compare-optimizer must differ from --optimizer-id")
+	}
+	return nil
+}
+
+// latestRecommendations returns the latest recommendation of each optimizer among the rows, by optimizer ID
+func latestRecommendations(rows []recommendationRow) map[string]recommendationRow {
+	latest := make(map[string]recommendationRow)
+	for _, row := range rows {
+		optimizerId := fmt.Sprintf("%v", row.EventAttributes["optimize.optimization.optimizer_id"])
+		if previous, ok := latest[optimizerId]; !ok || !row.Timestamp.Before(previous.Timestamp) {
+			latest[optimizerId] = row
+		}
+	}
+	return latest
+}
+
+// buildRecommendationDiff compares the latest recommendations of the primary and compared optimizers field by
+// field. Settings are compared as presented, i.e., after any --unit and --precision
+func (flags *recommendationsCmdFlags) buildRecommendationDiff(rows []recommendationRow) (recommendationDiff, error) {
+	latest := latestRecommendations(rows)
+	primary, ok := latest[flags.optimizerId]
+	if !ok {
+		return recommendationDiff{}, fmt.Errorf("no recommendation found for optimizer %q in the time interval", flags.optimizerId)
+	}
+	compared, ok := latest[flags.compareOptimizer]
+	if !ok {
+		return recommendationDiff{}, fmt.Errorf("no recommendation found for optimizer %q in the time interval", flags.compareOptimizer)
+	}
+
+	fields := []struct {
+		name  string
+		value func(recommendationRow) string
+	}{
+		{flags.unitConversions.label("cpu"), attributeValue("optimize.recommendation.settings.cpu")},
+		{flags.unitConversions.label("memory"), attributeValue("optimize.recommendation.settings.memory")},
+		{"State", attributeValue("optimize.recommendation.state")},
+		{"Blockers", func(row recommendationRow) string {
+			if len(row.Blockers) < 1 {
+				return "none"
+			}
+			return strings.Join(row.Blockers, ", ")
+		}},
+		{"Timestamp", func(row recommendationRow) string { return row.Timestamp.Format(time.RFC3339) }},
+	}
+	diff := recommendationDiff{
+		PrimaryOptimizerId:  flags.optimizerId,
+		ComparedOptimizerId: flags.compareOptimizer,
+		Items:               make([]recommendationFieldDiff, 0, len(fields)),
+	}
+	for _, field := range fields {
+		primaryValue, comparedValue := field.value(primary), field.value(compared)
+		diff.Items = append(diff.Items, recommendationFieldDiff{
+			Field:    field.name,
+			Primary:  primaryValue,
+			Compared: comparedValue,
+			Differs:  primaryValue != comparedValue,
+		})
+	}
+	diff.Total = len(diff.Items)
+	return diff, nil
+}
+
+func attributeValue(attribute string) func(recommendationRow) string {
+	return func(row recommendationRow) string {
+		value, ok := row.EventAttributes[attribute]
+		if !ok {
+			return ""
+		}
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+// printRecommendationDiff outputs the fields side by side, with the optimizer IDs as column headers. Differing
+// fields are marked, in color when the output is a color terminal
+func printRecommendationDiff(cmd *cobra.Command, diff recommendationDiff) {
+	colored := term.AllowsColorOutput(cmd.OutOrStdout())
+	lines := make([][]string, 0, len(diff.Items))
+	for _, item := range diff.Items {
+		marker := ""
+		if item.Differs {
+			marker = "≠"
+			if colored {
+				marker = categoryInvalidated.color + marker + colorReset
+			}
+		}
+		lines = append(lines, []string{marker, item.Field, item.Primary, item.Compared})
+	}
+	output.PrintCmdOutputCustom(cmd, diff, &output.Table{
+		Headers: []string{"", "Field", diff.PrimaryOptimizerId, diff.ComparedOptimizerId},
+		Lines:   lines,
+	})
+}
@@ -0,0 +1,111 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/output"
+)
+
+// recommendationPatch is a strategic merge patch applying the recommended settings to the optimized container
+type recommendationPatch struct {
+	WorkloadName string         `json:"workloadName"`
+	Namespace    string         `json:"namespace"`
+	OptimizerId  string         `json:"optimizerId"`
+	Patch        map[string]any `json:"patch"`
+}
+
+// buildRecommendationPatches converts the recommendations to kubernetes resource patches. The optimized workload and
+// container are looked up from each optimizer's configuration. Recommendations lacking both CPU and memory settings or
+// whose optimizer configuration can't be retrieved are skipped with a warning
+func buildRecommendationPatches(rows []recommendationRow, solutionName string) []recommendationPatch {
+	targets := make(map[string]K8SDeployment)
+	patches := make([]recommendationPatch, 0, len(rows))
+	for _, row := range rows {
+		optimizerId, _ := row.EventAttributes["optimize.optimization.optimizer_id"].(string)
+
+		resources := make(map[string]string, 2)
+		if cpu, ok := parseSetting(row.EventAttributes["optimize.recommendation.settings.cpu"]); ok {
+			resources["cpu"] = fmt.Sprintf("%vm", int64(math.Round(cpu*1000)))
+		}
+		if memory, ok := parseSetting(row.EventAttributes["optimize.recommendation.settings.memory"]); ok {
+			resources["memory"] = fmt.Sprintf("%vMi", int64(math.Round(memory*1024)))
+		}
+		if len(resources) < 1 {
+			log.Warnf("Recommendation for optimizer %q at %v has no CPU or memory settings, skipping patch", optimizerId, row.Timestamp)
+			continue
+		}
+
+		target, ok := targets[optimizerId]
+		if !ok {
+			optimizerConfig, err := getOptimizerConfig(optimizerId, "", solutionName)
+			if err != nil {
+				log.Warnf("Failed to retrieve configuration of optimizer %q, skipping patch: %v", optimizerId, err)
+				continue
+			}
+			target = optimizerConfig.Target.K8SDeployment
+			targets[optimizerId] = target
+		}
+
+		patches = append(patches, recommendationPatch{
+			WorkloadName: target.WorkloadName,
+			Namespace:    target.NamespaceName,
+			OptimizerId:  optimizerId,
+			Patch: map[string]any{
+				"spec": map[string]any{
+					"template": map[string]any{
+						"spec": map[string]any{
+							"containers": []any{
+								map[string]any{
+									"name": target.ContainerName,
+									"resources": map[string]any{
+										"requests": resources,
+										"limits":   resources,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+	return patches
+}
+
+// printRecommendationPatches outputs the patches. Human formats display each patch as a YAML document
+// so that it can be passed to kubectl patch directly, machine formats display the list of patches
+func printRecommendationPatches(cmd *cobra.Command, patches []recommendationPatch) error {
+	format, _ := cmd.Flags().GetString("output")
+	if format == "json" || format == "json-compact" || format == "yaml" {
+		output.PrintCmdOutput(cmd, struct {
+			Items []recommendationPatch `json:"items"`
+			Total int                   `json:"total"`
+		}{Items: patches, Total: len(patches)})
+		return nil
+	}
+	for _, patch := range patches {
+		output.PrintCmdStatus(cmd, fmt.Sprintf("---\n# kubectl patch deployment %v --namespace %v --patch-file <file>\n", patch.WorkloadName, patch.Namespace))
+		if err := output.PrintYaml(cmd, patch.Patch); err != nil {
+			return fmt.Errorf("output.PrintYaml: %w", err)
+		}
+	}
+	return nil
+}
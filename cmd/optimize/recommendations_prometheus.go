@@ -0,0 +1,108 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/output"
+)
+
+// prometheusOutputFormat selects the Prometheus text exposition format for the recommendations command
+const prometheusOutputFormat = "prometheus"
+
+// recommendationMetric is a recommended setting exported as a gauge
+type recommendationMetric struct {
+	attribute string
+	help      string
+}
+
+var recommendationMetrics = []recommendationMetric{
+	{attribute: "optimize.recommendation.settings.cpu", help: "Recommended CPU in cores"},
+	{attribute: "optimize.recommendation.settings.memory", help: "Recommended memory in GiB"},
+}
+
+// recommendationMetricLabels maps the label names of the gauges to the event attributes supplying their values
+var recommendationMetricLabels = []struct {
+	name      string
+	attribute string
+}{
+	{name: "optimizer_id", attribute: "optimize.optimization.optimizer_id"},
+	{name: "namespace", attribute: "k8s.namespace.name"},
+	{name: "workload", attribute: "k8s.workload.name"},
+}
+
+var invalidMetricNameChars = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+
+// prometheusMetricName converts an attribute name to a valid metric name, replacing invalid characters with
+// underscores and prefixing names that would start with a digit
+func prometheusMetricName(attribute string) string {
+	name := invalidMetricNameChars.ReplaceAllString(attribute, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// printRecommendationMetrics outputs the recommended settings as Prometheus gauges, one sample per recommendation
+// having the setting. Samples carry no timestamp as the Pushgateway rejects them
+func printRecommendationMetrics(cmd *cobra.Command, rows []EventsRow) {
+	var sb strings.Builder
+	for _, metric := range recommendationMetrics {
+		name := prometheusMetricName(metric.attribute)
+		samples := make([]string, 0, len(rows))
+		for _, row := range rows {
+			value, ok := parseSetting(row.EventAttributes[metric.attribute])
+			if !ok {
+				continue
+			}
+			samples = append(samples, fmt.Sprintf("%v%v %v\n", name, prometheusLabels(row), strconv.FormatFloat(value, 'g', -1, 64)))
+		}
+		if len(samples) < 1 {
+			continue
+		}
+		fmt.Fprintf(&sb, "# HELP %v %v\n# TYPE %v gauge\n", name, metric.help, name)
+		for _, sample := range samples {
+			sb.WriteString(sample)
+		}
+	}
+	output.PrintCmdStatus(cmd, sb.String())
+}
+
+// prometheusLabels formats the labels of the row's sample, omitting those whose attribute is missing or empty
+func prometheusLabels(row EventsRow) string {
+	labels := make([]string, 0, len(recommendationMetricLabels))
+	for _, label := range recommendationMetricLabels {
+		value, ok := row.EventAttributes[label.attribute]
+		if !ok || value == nil || fmt.Sprintf("%v", value) == "" {
+			continue
+		}
+		labels = append(labels, fmt.Sprintf("%v=\"%v\"", label.name, escapeLabelValue(fmt.Sprintf("%v", value))))
+	}
+	if len(labels) < 1 {
+		return ""
+	}
+	return "{" + strings.Join(labels, ",") + "}"
+}
+
+// escapeLabelValue escapes backslashes, double quotes and line feeds as required by the text exposition format
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
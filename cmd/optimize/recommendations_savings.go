@@ -0,0 +1,145 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/output"
+)
+
+// settingComparison pairs a recommended setting with the attribute holding the setting in place when the
+// optimization was baselined
+type settingComparison struct {
+	recommended string
+	current     string
+}
+
+var (
+	cpuComparison    = settingComparison{recommended: "optimize.recommendation.settings.cpu", current: "optimize.baseline.settings.cpu"}
+	memoryComparison = settingComparison{recommended: "optimize.recommendation.settings.memory", current: "optimize.baseline.settings.memory"}
+)
+
+// delta returns the current minus the recommended setting of the row, positive for savings. ok is false if the
+// row has no recommended setting; comparable is false if the recommended setting has no numeric current value
+func (c settingComparison) delta(row EventsRow) (delta float64, ok bool, comparable bool) {
+	recommended, ok := parseSetting(row.EventAttributes[c.recommended])
+	if !ok {
+		return 0, false, true
+	}
+	current, comparable := parseSetting(row.EventAttributes[c.current])
+	if !comparable {
+		return 0, true, false
+	}
+	return current - recommended, true, true
+}
+
+type resourceSavings struct {
+	CPUcores  float64 `json:"cpuCores" yaml:"cpuCores"`
+	MemoryGiB float64 `json:"memoryGiB" yaml:"memoryGiB"`
+}
+
+type workloadSavings struct {
+	OptimizerId     string `json:"optimizerId" yaml:"optimizerId"`
+	Namespace       string `json:"namespace" yaml:"namespace"`
+	WorkloadName    string `json:"workloadName" yaml:"workloadName"`
+	resourceSavings `yaml:",inline"`
+}
+
+type savingsReport struct {
+	Items    []workloadSavings `json:"items"`
+	Total    int               `json:"total"`
+	Savings  resourceSavings   `json:"savings"`
+	Excluded int               `json:"excluded"`
+}
+
+// buildSavingsReport sums the savings of the latest verified recommendation of each optimizer, per workload and
+// overall. Recommendations whose settings lack a current value to compare with are excluded and counted
+func buildSavingsReport(rows []recommendationRow) savingsReport {
+	latest := make(map[string]EventsRow)
+	for _, row := range rows {
+		if eventType(row.EventsRow) != "recommendation_verified" {
+			continue
+		}
+		optimizerId := fmt.Sprintf("%v", row.EventAttributes["optimize.optimization.optimizer_id"])
+		if previous, ok := latest[optimizerId]; !ok || !row.Timestamp.Before(previous.Timestamp) {
+			latest[optimizerId] = row.EventsRow
+		}
+	}
+
+	report := savingsReport{Items: make([]workloadSavings, 0, len(latest))}
+	for optimizerId, row := range latest {
+		cpu, hasCpu, cpuComparable := cpuComparison.delta(row)
+		memory, hasMemory, memoryComparable := memoryComparison.delta(row)
+		if (!hasCpu && !hasMemory) || !cpuComparable || !memoryComparable {
+			report.Excluded++
+			continue
+		}
+		namespace, _ := row.EventAttributes["k8s.namespace.name"].(string)
+		workloadName, _ := row.EventAttributes["k8s.workload.name"].(string)
+		report.Items = append(report.Items, workloadSavings{
+			OptimizerId:     optimizerId,
+			Namespace:       namespace,
+			WorkloadName:    workloadName,
+			resourceSavings: resourceSavings{CPUcores: cpu, MemoryGiB: memory},
+		})
+		report.Savings.CPUcores += cpu
+		report.Savings.MemoryGiB += memory
+	}
+	sort.Slice(report.Items, func(i, j int) bool {
+		a, b := report.Items[i], report.Items[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.WorkloadName != b.WorkloadName {
+			return a.WorkloadName < b.WorkloadName
+		}
+		return a.OptimizerId < b.OptimizerId
+	})
+	report.Total = len(report.Items)
+	return report
+}
+
+// printSavingsReport outputs the savings per workload followed by their totals. Savings are rounded to
+// --precision decimal places in human output, 3 by default
+func (flags *recommendationsCmdFlags) printSavingsReport(cmd *cobra.Command, report savingsReport) {
+	precision := flags.precision
+	if precision < 0 {
+		precision = 3
+	}
+	format := func(value float64) string {
+		scale := math.Pow10(precision)
+		return strconv.FormatFloat(math.Round(value*scale)/scale, 'f', -1, 64)
+	}
+	lines := make([][]string, 0, len(report.Items)+1)
+	for _, item := range report.Items {
+		lines = append(lines, []string{item.Namespace, item.WorkloadName, item.OptimizerId, format(item.CPUcores), format(item.MemoryGiB)})
+	}
+	lines = append(lines, []string{"TOTAL", "", "", format(report.Savings.CPUcores), format(report.Savings.MemoryGiB)})
+	output.PrintCmdOutputCustom(cmd, report, &output.Table{
+		Headers: []string{"Namespace", "Workload", "OptimizerId", "CPUcores", "MemoryGiB"},
+		Lines:   lines,
+	})
+	if report.Excluded > 0 {
+		if outputFormat, _ := cmd.Flags().GetString("output"); outputFormat != "json" && outputFormat != "json-compact" && outputFormat != "yaml" {
+			cmd.PrintErrf("Recommendations excluded from the totals as their current settings are unknown: %v\n", report.Excluded)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// settingThreshold bounds a numeric recommendation setting by the values of its min and max flags
+type settingThreshold struct {
+	attribute string
+	minFlag   string
+	min       float64
+	maxFlag   string
+	max       float64
+}
+
+func (flags *recommendationsCmdFlags) settingThresholds() []settingThreshold {
+	return []settingThreshold{
+		{attribute: "optimize.recommendation.settings.cpu", minFlag: "min-cpu", min: flags.minCpu, maxFlag: "max-cpu", max: flags.maxCpu},
+		{attribute: "optimize.recommendation.settings.memory", minFlag: "min-memory", min: flags.minMemory, maxFlag: "max-memory", max: flags.maxMemory},
+	}
+}
+
+// withinThresholds reports whether the recommended settings of the row lie within the bounds of the threshold
+// flags which have been set. Rows whose bounded settings are missing or not numeric are excluded unless
+// --keep-unparseable is set
+func (flags *recommendationsCmdFlags) withinThresholds(cmd *cobra.Command, row EventsRow) bool {
+	for _, threshold := range flags.settingThresholds() {
+		minSet := cmd.Flags().Changed(threshold.minFlag)
+		maxSet := cmd.Flags().Changed(threshold.maxFlag)
+		if !minSet && !maxSet {
+			continue
+		}
+		value, ok := parseSetting(row.EventAttributes[threshold.attribute])
+		if !ok {
+			if flags.keepUnparseable {
+				continue
+			}
+			return false
+		}
+		if (minSet && value < threshold.min) || (maxSet && value > threshold.max) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"path"
+)
+
+// redactedValue replaces the values of redacted attributes
+const redactedValue = "***"
+
+// attributeRedactions are the --redact patterns of the event attribute keys whose values are masked for presentation.
+// Patterns use path.Match syntax, so that e.g. optimize.principal.* matches all principal attributes
+type attributeRedactions []string
+
+// parseAttributeRedactions validates the --redact patterns
+func parseAttributeRedactions(patterns []string) (attributeRedactions, error) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+	}
+	return attributeRedactions(patterns), nil
+}
+
+// apply masks the values of the matching attribute keys of each row in place. It must be applied before aliasing,
+// as the patterns refer to the original attribute names
+func (redactions attributeRedactions) apply(rows []EventsRow) {
+	if len(redactions) == 0 {
+		return
+	}
+	for _, row := range rows {
+		redactions.applyToAttributes(row.EventAttributes)
+	}
+}
+
+// applyToAttributes masks the values of the matching keys of the attributes in place, e.g., of the blocker
+// attributes of recommendations, see apply
+func (redactions attributeRedactions) applyToAttributes(attributes map[string]any) {
+	for key := range attributes {
+		if redactions.matches(key) {
+			attributes[key] = redactedValue
+		}
+	}
+}
+
+func (redactions attributeRedactions) matches(key string) bool {
+	for _, pattern := range redactions {
+		// patterns were validated by parseAttributeRedactions
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttributeRedactions(t *testing.T) {
+	redactions, err := parseAttributeRedactions([]string{"optimize.principal.*", "k8s.cluster.id"})
+	require.NoError(t, err)
+
+	row := recommendationRow{
+		EventsRow:          EventsRow{EventAttributes: map[string]any{"optimize.principal.id": "user@example.com", "k8s.cluster.id": "c1", "appd.event.type": "recommendation_verified"}},
+		BlockersAttributes: map[string]any{"optimize.principal.type": "user", "optimize.blocker.x.reason": "r"},
+	}
+	redactions.apply([]EventsRow{row.EventsRow})
+	redactions.applyToAttributes(row.BlockersAttributes)
+	assert.Equal(t, map[string]any{"optimize.principal.id": redactedValue, "k8s.cluster.id": redactedValue, "appd.event.type": "recommendation_verified"}, row.EventAttributes)
+	assert.Equal(t, map[string]any{"optimize.principal.type": redactedValue, "optimize.blocker.x.reason": "r"}, row.BlockersAttributes)
+
+	_, err = parseAttributeRedactions([]string{"optimize.["})
+	assert.ErrorContains(t, err, `invalid redaction pattern "optimize.["`)
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/output"
+)
+
+// checkRelativeTime verifies whether --relative-time applies to the output format, adding the Age column if it
+// does. Ages are left out of the JSON and YAML output, which keeps the absolute timestamps only, as an age is not
+// stable once the output is stored
+func (flags *eventsCmdFlags) checkRelativeTime(cmd *cobra.Command) {
+	if !flags.relativeTime {
+		return
+	}
+	if format, _ := cmd.Flags().GetString("output"); format == "json" || format == "json-compact" || format == "yaml" {
+		flags.relativeTime = false
+		return
+	}
+	for _, name := range []string{output.TableFieldsAnnotation, output.DetailFieldsAnnotation} {
+		if spec, ok := cmd.Annotations[name]; ok {
+			cmd.Annotations[name] = spec + ", Age: .Age"
+		}
+	}
+}
+
+// annotateAges sets the Age of each row to the time elapsed since its timestamp, as of when the rows are printed
+func (flags *eventsCmdFlags) annotateAges(rows []EventsRow) {
+	if !flags.relativeTime {
+		return
+	}
+	now := time.Now()
+	for i := range rows {
+		rows[i].Age = humanizeAge(now.Sub(rows[i].Timestamp))
+	}
+}
+
+// humanizeAge formats the age in its two most significant units, e.g., "45s ago", "12m ago", "3h12m ago" or
+// "2d3h ago". Negative ages, of events timestamped ahead of the local clock, are formatted as "in 5s"
+func humanizeAge(age time.Duration) string {
+	if age < 0 {
+		return "in " + humanizeDuration(-age)
+	}
+	return humanizeDuration(age) + " ago"
+}
+
+func humanizeDuration(d time.Duration) string {
+	seconds := int64(d / time.Second)
+	days, hours, minutes := seconds/86400, seconds/3600%24, seconds/60%60
+	switch {
+	case seconds < 60:
+		return fmt.Sprintf("%ds", seconds)
+	case seconds < 3600:
+		return fmt.Sprintf("%dm", minutes)
+	case days < 1 && minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	case days < 1:
+		return fmt.Sprintf("%dh%dm", hours, minutes)
+	case hours == 0:
+		return fmt.Sprintf("%dd", days)
+	}
+	return fmt.Sprintf("%dd%dh", days, hours)
+}
@@ -0,0 +1,47 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHumanizeAge(t *testing.T) {
+	tests := []struct {
+		age      time.Duration
+		expected string
+	}{
+		{age: 0, expected: "0s ago"},
+		{age: 999 * time.Millisecond, expected: "0s ago"},
+		{age: 45 * time.Second, expected: "45s ago"},
+		{age: time.Minute, expected: "1m ago"},
+		{age: 12*time.Minute + 59*time.Second, expected: "12m ago"},
+		{age: time.Hour, expected: "1h ago"},
+		{age: 3*time.Hour + 12*time.Minute + 30*time.Second, expected: "3h12m ago"},
+		{age: 23*time.Hour + 59*time.Minute, expected: "23h59m ago"},
+		{age: 24 * time.Hour, expected: "1d ago"},
+		{age: 24*time.Hour + 59*time.Minute, expected: "1d ago"},
+		{age: 51*time.Hour + 30*time.Minute, expected: "2d3h ago"},
+		{age: 400 * 24 * time.Hour, expected: "400d ago"},
+		{age: -5 * time.Second, expected: "in 5s"},
+		{age: -(26*time.Hour + 5*time.Minute), expected: "in 1d2h"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, humanizeAge(test.age), test.age.String())
+	}
+}
@@ -94,13 +94,13 @@ func init() {
 func listReports(cmd *cobra.Command, args []string) error {
 	filtersList := make([]string, 0, 3)
 	if cluster != "" {
-		filtersList = append(filtersList, fmt.Sprintf("attributes(\"k8s.cluster.name\") = %q", cluster))
+		filtersList = append(filtersList, uql.AttributeEquals("k8s.cluster.name", cluster))
 	}
 	if namespace != "" {
-		filtersList = append(filtersList, fmt.Sprintf("attributes(\"k8s.namespace.name\") = %q", namespace))
+		filtersList = append(filtersList, uql.AttributeEquals("k8s.namespace.name", namespace))
 	}
 	if workloadName != "" {
-		filtersList = append(filtersList, fmt.Sprintf("attributes(\"k8s.workload.name\") = %q", workloadName))
+		filtersList = append(filtersList, uql.AttributeEquals("k8s.workload.name", workloadName))
 	}
 	tempVals.WorkloadFilters = strings.Join(filtersList, " && ")
 
@@ -125,7 +125,7 @@ func listReports(cmd *cobra.Command, args []string) error {
 
 	mainDataSet := resp.Main()
 	if mainDataSet == nil {
-		output.PrintCmdStatus(cmd, "No results found for given input\n")
+		printNoResults(cmd, "No results found for given input\n")
 		return nil
 	}
 
@@ -164,7 +164,7 @@ func listReports(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(reportRows) < 1 {
-		output.PrintCmdStatus(cmd, "No results found for given input\n")
+		printNoResults(cmd, "No results found for given input\n")
 		return nil
 	}
 
@@ -0,0 +1,58 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"time"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/cmd/uql"
+)
+
+// initialRetryDelay is the wait before the first retry of a failed page, doubled for each further retry of that page
+const initialRetryDelay = time.Second
+
+func init() {
+	optimizeCmd.PersistentFlags().Int("max-retries", 3, "Maximum number of retries of result pages that failed transiently, e.g., on a network failure or an HTTP 503, shared by all the pages retrieved by a command; 0 disables retrying")
+}
+
+// retryBudget bounds the retries of failed page continuations across all the queries of a command invocation,
+// so that a flaky connection cannot stall a large export indefinitely. A nil budget allows no retries
+type retryBudget struct {
+	remaining int
+}
+
+func newRetryBudget(cmd *cobra.Command) *retryBudget {
+	maxRetries, _ := cmd.Flags().GetInt("max-retries")
+	return &retryBudget{remaining: maxRetries}
+}
+
+// continueQuery continues the data set like uql.UqlClient.ContinueQuery, retrying only the failed page while the
+// budget allows; the rows collected from previous pages are left to the caller and are unaffected. Only transient
+// failures are retried, see uql.IsTransient, so that, e.g., authentication failures are reported right away
+func (budget *retryBudget) continueQuery(dataSet *uql.DataSet, rel string, page int) (*uql.Response, error) {
+	resp, err := uql.ClientV1.ContinueQuery(dataSet, rel)
+	delay := initialRetryDelay
+	for err != nil && uql.IsTransient(err) && budget != nil && budget.remaining > 0 {
+		budget.remaining--
+		log.Warnf("Retrieving page %v failed, retrying in %v (%v retries left): %v", page, delay, budget.remaining, err)
+		time.Sleep(delay)
+		delay *= 2
+		resp, err = uql.ClientV1.ContinueQuery(dataSet, rel)
+	}
+	return resp, err
+}
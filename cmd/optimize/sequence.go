@@ -0,0 +1,48 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/output"
+)
+
+// eventSequencer numbers the events of each optimizer ID, keeping the last number assigned per optimizer so that
+// followed batches continue the sequence. A nil sequencer assigns nothing
+type eventSequencer map[string]int
+
+// assign sorts the rows by timestamp and sets the Seq of each row to its 1-based ordinal among the rows of its
+// optimizer ID
+func (sequencer eventSequencer) assign(rows []EventsRow) {
+	if sequencer == nil {
+		return
+	}
+	sortByTimestamp(rows)
+	for i := range rows {
+		optimizerId, _ := rows[i].EventAttributes["optimize.optimization.optimizer_id"].(string)
+		sequencer[optimizerId]++
+		rows[i].Seq = sequencer[optimizerId]
+	}
+}
+
+// addSequenceColumn makes the sequence number the leading column of the command's human output
+func addSequenceColumn(cmd *cobra.Command) {
+	for _, name := range []string{output.TableFieldsAnnotation, output.DetailFieldsAnnotation} {
+		if spec, ok := cmd.Annotations[name]; ok {
+			cmd.Annotations[name] = "Seq: .Seq, " + spec
+		}
+	}
+}
@@ -142,16 +142,12 @@ func getServoLogs(flags *servoLogsFlags) func(*cobra.Command, []string) error {
 
 		main_data_set := resp.Main()
 		if main_data_set == nil || len(main_data_set.Data) < 1 {
-			output.PrintCmdStatus(cmd, "No servo logs results found for given input\n")
+			printNoResults(cmd, "No servo logs results found for given input\n")
 			return nil
 		}
-		if len(main_data_set.Data[0]) < 1 {
-			return fmt.Errorf("main dataset %v first row has no columns", main_data_set.Name)
-		}
-
-		data_set, ok := main_data_set.Data[0][0].(*uql.DataSet)
-		if !ok {
-			return fmt.Errorf("main dataset %v first row first column (type %T) could not be converted to *uql.DataSet", main_data_set.Name, main_data_set.Data[0][0])
+		data_set, err := uql.FirstNestedDataSet(resp)
+		if err != nil {
+			return err
 		}
 		logRows, err := extractLogsData(data_set)
 		if err != nil {
@@ -184,15 +180,9 @@ func getServoLogs(flags *servoLogsFlags) func(*cobra.Command, []string) error {
 				log.Errorf("Continuation of servo logs query (page %v) has nil main data. Returned data may not be complete!", page)
 				break
 			}
-			if len(main_data_set.Data) < 1 {
-				return fmt.Errorf("page %v main dataset %v has no rows", page, main_data_set.Name)
-			}
-			if len(main_data_set.Data[0]) < 1 {
-				return fmt.Errorf("page %v main dataset %v first row has no columns", page, main_data_set.Name)
-			}
-			data_set, ok = main_data_set.Data[0][0].(*uql.DataSet)
-			if !ok {
-				return fmt.Errorf("page %v main dataset %v first row first column (type %T) could not be converted to *uql.DataSet", page, main_data_set.Name, main_data_set.Data[0][0])
+			data_set, err = uql.NestedDataSet(main_data_set)
+			if err != nil {
+				return fmt.Errorf("page %v %w", page, err)
 			}
 
 			newRows, err := extractLogsData(data_set)
@@ -0,0 +1,72 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+// eventSnapshot maintains the --snapshot-file holding all the events printed while following, so that readers
+// tailing it always see a complete document rather than an append log
+type eventSnapshot struct {
+	path    string
+	flatten bool
+	rows    []EventsRow
+}
+
+// add appends the printed rows and replaces the snapshot file. Failures are logged rather than returned so that
+// following continues on stdout. A nil snapshot does nothing
+func (s *eventSnapshot) add(cmd *cobra.Command, rows []EventsRow) {
+	if s == nil {
+		return
+	}
+	s.rows = append(s.rows, rows...)
+	if err := s.write(cmd); err != nil {
+		log.Warnf("Failed to write snapshot file %q: %v", s.path, err)
+	}
+}
+
+// write formats the rows as they would be printed to stdout into a temporary file next to the snapshot file, then
+// renames it over the snapshot file, which is atomic on the same file system
+func (s *eventSnapshot) write(cmd *cobra.Command) error {
+	file, err := os.CreateTemp(filepath.Dir(s.path), "."+filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp: %w", err)
+	}
+	defer os.Remove(file.Name()) // no-op once renamed
+	if err := file.Chmod(0644); err != nil {
+		// temporary files are private, the snapshot is meant to be read by others, as files created by os.Create
+		file.Close()
+		return fmt.Errorf("failed to set permissions of %q: %w", file.Name(), err)
+	}
+
+	previous := cmd.OutOrStdout()
+	cmd.SetOut(file)
+	printEventRows(cmd, s.rows, responseWarnings.drain(), s.flatten, nil)
+	cmd.SetOut(previous)
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close %q: %w", file.Name(), err)
+	}
+	if err := os.Rename(file.Name(), s.path); err != nil {
+		return fmt.Errorf("os.Rename: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"os"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+)
+
+// solutionNameEnvVar names the environment variable overriding the default of the hidden --solution-name flag
+const solutionNameEnvVar = "FSOC_OPTIMIZE_SOLUTION"
+
+// SubsystemConfig defines the subsystem configuration under fsoc
+type SubsystemConfig struct {
+	SolutionName string `mapstructure:"solution,omitempty" fsoc-help:"Intended for developer usage, name of the solution defining the optimize types, used unless --solution-name or FSOC_OPTIMIZE_SOLUTION is set. The default is \"optimize\"."`
+}
+
+var GlobalConfig SubsystemConfig
+
+// applySolutionName wraps the RunE of the command and its subcommands so that the solution name defaults to
+// FSOC_OPTIMIZE_SOLUTION, then to the "solution" setting of the optimize config, when --solution-name isn't given.
+// The flag keeps precedence, and all the commands having it share the same default
+func applySolutionName(command *cobra.Command) {
+	if runE := command.RunE; runE != nil {
+		command.RunE = func(cmd *cobra.Command, args []string) error {
+			if flag := cmd.Flags().Lookup("solution-name"); flag != nil && !flag.Changed {
+				if name := defaultSolutionName(); name != "" {
+					log.Infof("Using solution name %q instead of %q", name, flag.Value.String())
+					if err := flag.Value.Set(name); err != nil {
+						return err
+					}
+				}
+			}
+			return runE(cmd, args)
+		}
+	}
+	for _, subcommand := range command.Commands() {
+		applySolutionName(subcommand)
+	}
+}
+
+// defaultSolutionName returns the solution name set by the environment or the config, empty if neither sets it
+func defaultSolutionName() string {
+	if name := os.Getenv(solutionNameEnvVar); name != "" {
+		return name
+	}
+	return GlobalConfig.SolutionName
+}
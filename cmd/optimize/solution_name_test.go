@@ -0,0 +1,73 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSolutionNamePrecedence(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		env      string
+		config   string
+		expected string
+	}{
+		{name: "built-in default", expected: "optimize"},
+		{name: "config", config: "optimize-config", expected: "optimize-config"},
+		{name: "environment over config", env: "optimize-env", config: "optimize-config", expected: "optimize-env"},
+		{name: "flag over environment and config", args: []string{"--solution-name", "optimize-flag"}, env: "optimize-env", config: "optimize-config", expected: "optimize-flag"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(solutionNameEnvVar, test.env)
+			previous := GlobalConfig
+			GlobalConfig.SolutionName = test.config
+			t.Cleanup(func() { GlobalConfig = previous })
+
+			solutionName := ""
+			parent := &cobra.Command{Use: "optimize"}
+			command := &cobra.Command{
+				Use:  "events",
+				RunE: func(cmd *cobra.Command, args []string) error { return nil },
+			}
+			command.Flags().StringVar(&solutionName, "solution-name", "optimize", "")
+			parent.AddCommand(command)
+			applySolutionName(parent)
+
+			require.NoError(t, command.ParseFlags(test.args))
+			require.NoError(t, command.RunE(command, nil))
+			assert.Equal(t, test.expected, solutionName)
+		})
+	}
+}
+
+func TestDefaultSolutionName(t *testing.T) {
+	previous := GlobalConfig
+	t.Cleanup(func() { GlobalConfig = previous })
+
+	t.Setenv(solutionNameEnvVar, "")
+	GlobalConfig.SolutionName = ""
+	assert.Equal(t, "", defaultSolutionName(), "the flag default applies when neither sets a name")
+	GlobalConfig.SolutionName = "optimize-config"
+	assert.Equal(t, "optimize-config", defaultSolutionName())
+	t.Setenv(solutionNameEnvVar, "optimize-env")
+	assert.Equal(t, "optimize-env", defaultSolutionName())
+}
@@ -0,0 +1,143 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// timestampSortField selects sorting by the event timestamp rather than by an event attribute
+const timestampSortField = "Timestamp"
+
+// recommendationSortKeys are the shorthands accepted by recommendations --sort-by for the reviewed attributes
+var recommendationSortKeys = map[string]string{
+	"optimizer-id": "optimize.optimization.optimizer_id",
+	"state":        "optimize.recommendation.state",
+	"timestamp":    timestampSortField,
+	"cpu":          "optimize.recommendation.settings.cpu",
+	"memory":       "optimize.recommendation.settings.memory",
+}
+
+// sortRowsByFields stably sorts rows by a comma separated list of fields, each a shorthand of keys or an event
+// attribute name, see sortRowsByField. Rows equal in the first field are ordered by the second, and so on
+func sortRowsByFields[T any](rows []T, eventsRow func(T) EventsRow, fields string, keys map[string]string, desc bool) {
+	if fields == "" {
+		return
+	}
+	names := strings.Split(fields, ",")
+	// sorting stably by the last field first leaves the rows ordered by all fields
+	for i := len(names) - 1; i >= 0; i-- {
+		field := strings.TrimSpace(names[i])
+		if attribute, ok := keys[strings.ToLower(field)]; ok {
+			field = attribute
+		}
+		sortRowsByField(rows, eventsRow, field, desc)
+	}
+}
+
+// sortRowsByField stably sorts rows by the named event attribute (or by timestamp for "Timestamp"), in ascending order
+// unless desc is set. Numbers and timestamps are compared by value, other values by their string representation, and
+// numbers precede values that aren't numbers. Rows missing the attribute are placed last in either order
+func sortRowsByField[T any](rows []T, eventsRow func(T) EventsRow, field string, desc bool) {
+	if field == "" {
+		return
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		left, leftOk := sortValue(eventsRow(rows[i]), field)
+		right, rightOk := sortValue(eventsRow(rows[j]), field)
+		if !leftOk || !rightOk {
+			return leftOk && !rightOk
+		}
+		if desc {
+			return compareSortValues(right, left) < 0
+		}
+		return compareSortValues(left, right) < 0
+	})
+}
+
+func sortValue(row EventsRow, field string) (any, bool) {
+	if field == timestampSortField {
+		return row.Timestamp, true
+	}
+	value, ok := row.EventAttributes[field]
+	return value, ok && value != nil
+}
+
+func compareSortValues(left, right any) int {
+	switch l := left.(type) {
+	case time.Time:
+		if r, ok := right.(time.Time); ok {
+			return l.Compare(r)
+		}
+	}
+	l, leftNumber := sortNumber(left)
+	r, rightNumber := sortNumber(right)
+	switch {
+	case leftNumber && rightNumber:
+		return compareOrdered(l, r)
+	case leftNumber != rightNumber:
+		// order mixed numeric and non-numeric values consistently rather than by their representation
+		if leftNumber {
+			return -1
+		}
+		return 1
+	}
+	return compareOrdered(fmt.Sprintf("%v", left), fmt.Sprintf("%v", right))
+}
+
+func sortNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func compareOrdered[T int | float64 | string](left, right T) int {
+	switch {
+	case left < right:
+		return -1
+	case left > right:
+		return 1
+	}
+	return 0
+}
+
+// stableOrderAttributes break ties between events of identical timestamps, in order. UQL orders fetched events by
+// timestamp only, so the tiebreakers are applied client-side
+var stableOrderAttributes = []string{"appd.event.type", "optimize.optimization.optimizer_id"}
+
+// sortStableOrder sorts rows by timestamp, then by the stableOrderAttributes, so that the order of events with
+// identical timestamps is the same across runs
+func sortStableOrder(rows []EventsRow) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		if c := rows[i].Timestamp.Compare(rows[j].Timestamp); c != 0 {
+			return c < 0
+		}
+		for _, attr := range stableOrderAttributes {
+			left := fmt.Sprintf("%v", rows[i].EventAttributes[attr])
+			right := fmt.Sprintf("%v", rows[j].EventAttributes[attr])
+			if c := compareOrdered(left, right); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+}
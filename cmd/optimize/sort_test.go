@@ -0,0 +1,73 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sortTestRows returns rows identified by their "id" attribute, with mixed number, string and missing "value"
+// attributes and a "group" attribute to sort by first
+func sortTestRows() []EventsRow {
+	values := []struct {
+		id    string
+		group string
+		value any
+	}{
+		{"two", "x", 2.0},
+		{"b", "y", "b"},
+		{"missing", "x", nil},
+		{"ten", "y", 10.0},
+		{"a", "x", "a"},
+	}
+	rows := make([]EventsRow, 0, len(values))
+	for _, v := range values {
+		attributes := map[string]any{"id": v.id, "group": v.group}
+		if v.value != nil {
+			attributes["value"] = v.value
+		}
+		rows = append(rows, EventsRow{EventAttributes: attributes})
+	}
+	return rows
+}
+
+func TestSortRowsByFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   string
+		desc     bool
+		expected []string
+	}{
+		{name: "ascending", fields: "value", expected: []string{"two", "ten", "a", "b", "missing"}},
+		{name: "descending", fields: "value", desc: true, expected: []string{"b", "a", "ten", "two", "missing"}},
+		{name: "shorthand and second field", fields: "Group, val", expected: []string{"two", "a", "missing", "ten", "b"}},
+		{name: "second field descending", fields: "group,value", desc: true, expected: []string{"b", "ten", "a", "two", "missing"}},
+		{name: "no fields", fields: "", expected: []string{"two", "b", "missing", "ten", "a"}},
+	}
+	keys := map[string]string{"group": "group", "val": "value"}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows := sortTestRows()
+			sortRowsByFields(rows, func(row EventsRow) EventsRow { return row }, test.fields, keys, test.desc)
+			ids := make([]string, 0, len(rows))
+			for _, row := range rows {
+				ids = append(ids, row.EventAttributes["id"].(string))
+			}
+			assert.Equal(t, test.expected, ids)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/output"
+)
+
+// sqliteDriverName is the database/sql driver registered by the pure Go SQLite driver, which is linked in by
+// building with the sqlite tag, see sqlite_driver.go. The --sqlite flag is only registered by such builds, as the
+// driver is not a dependency of the default build
+const sqliteDriverName = "sqlite"
+
+const sqliteCreateTable = `CREATE TABLE IF NOT EXISTS events (
+	timestamp TEXT NOT NULL,
+	event_type TEXT,
+	optimizer_id TEXT,
+	namespace TEXT,
+	workload TEXT,
+	attributes TEXT
+)`
+
+const sqliteInsertEvent = `INSERT INTO events (timestamp, event_type, optimizer_id, namespace, workload, attributes) VALUES (?, ?, ?, ?, ?, ?)`
+
+// writeRowsToSqlite inserts the rows into the events table of the SQLite database at path, creating both the
+// database and the table if needed, in a single transaction. Attributes are stored as a JSON object under their
+// original names, so that the rows of several exports can be queried together
+func writeRowsToSqlite(cmd *cobra.Command, path string, rows []EventsRow) error {
+	if !slices.Contains(sql.Drivers(), sqliteDriverName) {
+		return fmt.Errorf("--sqlite is not available, fsoc was built without SQLite support (build tag sqlite)")
+	}
+	db, err := sql.Open(sqliteDriverName, path)
+	if err != nil {
+		return fmt.Errorf("failed to open SQLite database %q: %w", path, err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(sqliteCreateTable); err != nil {
+		return fmt.Errorf("failed to create events table: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // no-op once committed
+	insert, err := tx.Prepare(sqliteInsertEvent)
+	if err != nil {
+		return fmt.Errorf("failed to prepare insert: %w", err)
+	}
+	defer insert.Close()
+	for _, row := range rows {
+		attributes, err := json.Marshal(row.EventAttributes)
+		if err != nil {
+			return fmt.Errorf("failed to encode attributes of event at %v: %w", row.Timestamp, err)
+		}
+		_, err = insert.Exec(
+			row.Timestamp.UTC().Format(time.RFC3339Nano),
+			row.EventAttributes["appd.event.type"],
+			row.EventAttributes["optimize.optimization.optimizer_id"],
+			row.EventAttributes["k8s.namespace.name"],
+			row.EventAttributes["k8s.workload.name"],
+			string(attributes),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert event at %v: %w", row.Timestamp, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	output.PrintCmdStatus(cmd, fmt.Sprintf("Inserted %v events into %v\n", len(rows), path))
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/output"
+)
+
+// eventStages tracks the stage each optimizer is in, keeping it across calls so that followed batches continue
+// the grouping. A nil eventStages annotates nothing
+type eventStages map[string]*optimizerStage
+
+type optimizerStage struct {
+	label   string
+	started int
+}
+
+// newEventStages returns the stage tracker for --annotate-stages, nil for the JSON and YAML output formats whose
+// rows are left unchanged
+func newEventStages(cmd *cobra.Command) eventStages {
+	if format, _ := cmd.Flags().GetString("output"); format == "json" || format == "json-compact" || format == "yaml" {
+		return nil
+	}
+	return make(eventStages)
+}
+
+// annotate sorts the rows by timestamp and sets the Stage of each row to the stage started by the latest
+// stage_started event of its optimizer ID, up to and including the matching stage_ended event. Stages are labeled
+// by their optimize.stage.num or, if missing, by their ordinal among the optimizer's started stages
+func (stages eventStages) annotate(rows []EventsRow) {
+	if stages == nil {
+		return
+	}
+	sortByTimestamp(rows)
+	for i := range rows {
+		optimizerId, _ := rows[i].EventAttributes["optimize.optimization.optimizer_id"].(string)
+		stage, ok := stages[optimizerId]
+		if !ok {
+			stage = &optimizerStage{}
+			stages[optimizerId] = stage
+		}
+		switch eventType(rows[i]) {
+		case "stage_started":
+			stage.started++
+			stage.label = fmt.Sprintf("%v", stage.started)
+			if num, ok := rows[i].EventAttributes["optimize.stage.num"]; ok {
+				stage.label = fmt.Sprintf("%v", num)
+			}
+			rows[i].Stage = stage.label
+		case "stage_ended":
+			rows[i].Stage = stage.label
+			stage.label = ""
+		default:
+			rows[i].Stage = stage.label
+		}
+	}
+}
+
+// addStageColumn makes the stage the leading column of the command's human output
+func addStageColumn(cmd *cobra.Command) {
+	for _, name := range []string{output.TableFieldsAnnotation, output.DetailFieldsAnnotation} {
+		if spec, ok := cmd.Annotations[name]; ok {
+			cmd.Annotations[name] = "Stage: (.Stage // \"-\"), " + spec
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/cmd/uql"
+)
+
+// commandStats tallies the pages retrieved by all the UQL queries of a command and the time elapsed, for the
+// --stats footer. A nil commandStats tallies nothing
+type commandStats struct {
+	start    time.Time
+	original uql.UqlClient
+	client   *uql.TimingClient
+}
+
+// startStats instruments the UQL client to count the pages retrieved, if --stats is set
+func startStats(flags *eventsFlags) *commandStats {
+	if !flags.stats {
+		return nil
+	}
+	stats := &commandStats{start: time.Now(), original: uql.ClientV1}
+	stats.client = uql.NewTimingClient(stats.original)
+	uql.ClientV1 = stats.client
+	return stats
+}
+
+// stop restores the UQL client, returning the number of pages retrieved in the meantime
+func (stats *commandStats) stop() int {
+	if stats == nil || stats.client == nil {
+		return 0
+	}
+	uql.ClientV1 = stats.original
+	pages := 0
+	for _, timing := range stats.client.Timings() {
+		pages += timing.Pages
+	}
+	stats.client = nil
+	return pages
+}
+
+// finish stops the tally and, for the human output formats, prints the footer to stderr. It should be called
+// once the output has been printed
+func (stats *commandStats) finish(cmd *cobra.Command, rowCount int) {
+	if stats == nil || stats.client == nil {
+		return
+	}
+	pages := stats.stop()
+	if format, _ := cmd.Flags().GetString("output"); format == "json" || format == "json-compact" || format == "yaml" {
+		return
+	}
+	cmd.PrintErrf("%v rows, %v pages fetched in %v\n", rowCount, pages, time.Since(stats.start).Round(time.Millisecond))
+}
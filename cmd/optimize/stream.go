@@ -0,0 +1,69 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/output"
+)
+
+// eventStream writes the events to the JSON output page by page, as they are retrieved, instead of accumulating
+// all of them before printing. A nil eventStream is not streaming
+type eventStream struct {
+	json    *output.JsonStream
+	flatten bool
+}
+
+// newEventStream returns the stream of the events output for --stream, nil without it
+func (flags *eventsCmdFlags) newEventStream(cmd *cobra.Command) (*eventStream, error) {
+	if !flags.stream {
+		return nil, nil
+	}
+	json, err := output.NewCmdJsonStream(cmd, "items")
+	if err != nil {
+		return nil, err
+	}
+	return &eventStream{json: json, flatten: flags.flatten}, nil
+}
+
+// add filters and presents the rows of a page as the accumulated events would be, then writes them
+func (s *eventStream) add(flags *eventsCmdFlags, rows []EventsRow) error {
+	if flags.normalizeIds {
+		normalizeOptimizerIds(rows)
+	}
+	rows = flags.filterByProgress(flags.filterByAttributePresence(rows))
+	flags.presentNumericAttributes(rows)
+	flags.redactions.apply(rows)
+	flags.aliasMap.apply(rows)
+	for _, row := range rows {
+		var item any = row
+		if s.flatten {
+			item = flattenEventRow(row)
+		}
+		if err := s.json.Add(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// close ends the output with the total of the events written and the warnings of the query
+func (s *eventStream) close() error {
+	return s.json.Close(struct {
+		Total    int            `json:"total"`
+		Warnings []queryWarning `json:"warnings,omitempty"`
+	}{Total: s.json.Count(), Warnings: responseWarnings.drain()})
+}
@@ -0,0 +1,134 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// resourceUnits describes the units a recommended setting can be converted to with --unit. Values are retrieved in
+// the base unit, whose factor is 1, and the table column named after the base unit is renamed after the chosen unit
+type resourceUnits struct {
+	attribute string
+	column    string             // column prefix, e.g., CPU for the CPUcores column
+	base      string             // unit of the retrieved values
+	factors   map[string]float64 // multiplier from the base unit, by canonical unit name
+	// bytesThreshold, if set, is the value from which a retrieved value is taken to be in bytes rather than
+	// in the base unit, as memory settings are occasionally reported in bytes
+	bytesThreshold float64
+}
+
+const gib = 1 << 30
+
+var resourceUnitsByName = map[string]resourceUnits{
+	"cpu": {
+		attribute: "optimize.recommendation.settings.cpu",
+		column:    "CPU",
+		base:      "cores",
+		factors:   map[string]float64{"cores": 1, "millicores": 1000},
+	},
+	"memory": {
+		attribute:      "optimize.recommendation.settings.memory",
+		column:         "Memory",
+		base:           "GiB",
+		factors:        map[string]float64{"bytes": gib, "KiB": 1 << 20, "MiB": 1 << 10, "GiB": 1, "TiB": 1.0 / (1 << 10)},
+		bytesThreshold: 1 << 20,
+	},
+}
+
+// attributeUnit is the unit chosen for a resource's setting
+type attributeUnit struct {
+	resourceUnits
+	unit string
+}
+
+// attributeUnits are the --unit conversions
+type attributeUnits []attributeUnit
+
+// parseAttributeUnits parses the --unit values of the form resource=unit, e.g., cpu=millicores. Units are matched
+// regardless of case
+func parseAttributeUnits(values []string) (attributeUnits, error) {
+	units := make(attributeUnits, 0, len(values))
+	for _, value := range values {
+		resource, unit, found := strings.Cut(value, "=")
+		resources, ok := resourceUnitsByName[strings.ToLower(strings.TrimSpace(resource))]
+		if !found || !ok {
+			return nil, fmt.Errorf("invalid unit %q, expected the form resource=unit with resource one of: %v", value, strings.Join(sortedKeys(resourceUnitsByName), ", "))
+		}
+		canonical := ""
+		for name := range resources.factors {
+			if strings.EqualFold(name, strings.TrimSpace(unit)) {
+				canonical = name
+			}
+		}
+		if canonical == "" {
+			return nil, fmt.Errorf("unknown unit %q for %v, expected one of: %v", unit, resource, strings.Join(sortedKeys(resources.factors), ", "))
+		}
+		units = append(units, attributeUnit{resourceUnits: resources, unit: canonical})
+	}
+	return units, nil
+}
+
+// apply converts the setting values of each row in place. Values must have been normalized to numbers, others are
+// left unchanged
+func (units attributeUnits) apply(rows []EventsRow) {
+	for _, unit := range units {
+		factor := unit.factors[unit.unit]
+		for _, row := range rows {
+			value, ok := row.EventAttributes[unit.attribute].(float64)
+			if !ok {
+				continue
+			}
+			if unit.bytesThreshold > 0 && value >= unit.bytesThreshold {
+				value /= unit.factors["bytes"]
+			}
+			row.EventAttributes[unit.attribute] = value * factor
+		}
+	}
+}
+
+// applyToAnnotations renames the columns named after the base units, e.g., CPUcores, after the chosen units
+func (units attributeUnits) applyToAnnotations(cmd *cobra.Command) {
+	for name, spec := range cmd.Annotations {
+		for _, unit := range units {
+			spec = strings.ReplaceAll(spec, unit.column+unit.base+":", unit.column+unit.unit+":")
+		}
+		cmd.Annotations[name] = spec
+	}
+}
+
+// label returns the column name of the resource's setting, e.g., CPUcores, after the chosen unit if converted
+func (units attributeUnits) label(resource string) string {
+	resources := resourceUnitsByName[resource]
+	for _, unit := range units {
+		if unit.attribute == resources.attribute {
+			return resources.column + unit.unit
+		}
+	}
+	return resources.column + resources.base
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttributeUnits(t *testing.T) {
+	const cpu, memory = "optimize.recommendation.settings.cpu", "optimize.recommendation.settings.memory"
+	tests := []struct {
+		unit     string
+		value    any
+		expected any
+		label    string
+	}{
+		{unit: "cpu=millicores", value: 0.25, expected: 250.0, label: "CPUmillicores"},
+		{unit: "CPU=Cores", value: 1.5, expected: 1.5, label: "CPUcores"},
+		{unit: "memory=MiB", value: 0.5, expected: 512.0, label: "MemoryMiB"},
+		{unit: "memory=KiB", value: 2.0, expected: 2097152.0, label: "MemoryKiB"},
+		{unit: "memory=bytes", value: 1.0, expected: 1073741824.0, label: "Memorybytes"},
+		{unit: "memory=TiB", value: 512.0, expected: 0.5, label: "MemoryTiB"},
+		{unit: "memory=GiB", value: 2147483648.0, expected: 2.0, label: "MemoryGiB"},
+		{unit: "memory=MiB", value: 2147483648.0, expected: 2048.0, label: "MemoryMiB"},
+		{unit: "memory=MiB", value: "1Gi", expected: "1Gi", label: "MemoryMiB"},
+	}
+	for _, test := range tests {
+		units, err := parseAttributeUnits([]string{test.unit})
+		require.NoError(t, err, test.unit)
+		row := EventsRow{EventAttributes: map[string]any{cpu: test.value, memory: test.value}}
+		units.apply([]EventsRow{row})
+		attribute, resource := memory, "memory"
+		if units[0].attribute == cpu {
+			attribute, resource = cpu, "cpu"
+		}
+		assert.Equal(t, test.expected, row.EventAttributes[attribute], test.unit)
+		assert.Equal(t, test.label, units.label(resource), test.unit)
+	}
+
+	_, err := parseAttributeUnits([]string{"disk=GiB"})
+	assert.ErrorContains(t, err, "resource one of: cpu, memory")
+	_, err = parseAttributeUnits([]string{"memory=GB"})
+	assert.ErrorContains(t, err, `unknown unit "GB" for memory, expected one of: GiB, KiB, MiB, TiB, bytes`)
+}
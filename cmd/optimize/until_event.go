@@ -0,0 +1,85 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+// followStop tracks the --until-event condition ending a follow once the event type was printed for the
+// followed optimizers
+type followStop struct {
+	eventType string
+
+	// awaiting holds the optimizer IDs for which the event type has not been seen yet. When the events are not
+	// filtered by optimizer, it is nil and the first event of the type ends the follow
+	awaiting map[string]bool
+}
+
+// newFollowStop validates --until-event against the retrieved event types and returns the stop condition for the
+// given optimizer IDs, or nil without --until-event
+func (flags *eventsCmdFlags) newFollowStop(optimizerIds []string) (*followStop, error) {
+	if flags.untilEvent == "" {
+		return nil, nil
+	}
+	if !flags.follow {
+		return nil, fmt.Errorf("--until-event requires --follow")
+	}
+	untilEvent := unqualifiedEventType(flags.untilEvent)
+	retrieved := slices.ContainsFunc(flags.events, func(event string) bool {
+		return unqualifiedEventType(event) == untilEvent
+	})
+	if !retrieved {
+		return nil, fmt.Errorf("--until-event %q is not one of the retrieved event types, add it to --events", flags.untilEvent)
+	}
+	stop := &followStop{eventType: untilEvent}
+	if len(optimizerIds) > 0 {
+		stop.awaiting = make(map[string]bool, len(optimizerIds))
+		for _, optimizerId := range optimizerIds {
+			stop.awaiting[optimizerId] = true
+		}
+	}
+	return stop, nil
+}
+
+// reached records the rows about to be printed, before aliasing, and reports whether the event type has now been
+// seen for all the awaited optimizers. A nil stop is never reached
+func (s *followStop) reached(rows []EventsRow) bool {
+	if s == nil {
+		return false
+	}
+	for _, row := range rows {
+		if eventType(row) != s.eventType {
+			continue
+		}
+		if s.awaiting == nil {
+			return true
+		}
+		optimizerId, _ := row.EventAttributes["optimize.optimization.optimizer_id"].(string)
+		delete(s.awaiting, optimizerId)
+		if len(s.awaiting) < 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// printStopped reports to stderr that following stopped because the --until-event was seen
+func (s *followStop) printStopped(cmd *cobra.Command) {
+	cmd.PrintErrf("Stopped following events after %v\n", s.eventType)
+}
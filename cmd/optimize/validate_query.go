@@ -0,0 +1,125 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/cmd/uql"
+	"github.com/cisco-open/fsoc/output"
+)
+
+type validateQueryFlags struct {
+	file string
+}
+
+func init() {
+	// TODO move this logic to optimize root when implementing unit tests
+	optimizeCmd.AddCommand(NewCmdValidateQuery())
+}
+
+func NewCmdValidateQuery() *cobra.Command {
+	flags := validateQueryFlags{}
+	command := &cobra.Command{
+		Use:   "validate-query",
+		Short: "Check a UQL query for errors without retrieving its data",
+		Long: `
+Check a UQL query for errors without retrieving its data
+
+The backend offers no separate validation endpoint, so the query is executed and the errors it reports are listed.
+Queries fetching events without a LIMITS clause are limited to a single event to keep the check cheap.
+`,
+		Example: `  fsoc optimize validate-query --file query.uql
+  cat query.uql | fsoc optimize validate-query --file -`,
+		Args:             cobra.NoArgs,
+		RunE:             validateQuery(&flags),
+		TraverseChildren: true,
+	}
+	command.Flags().StringVarP(&flags.file, "file", "f", "", "Read the query from the given file, or from stdin if -")
+	if err := command.MarkFlagRequired("file"); err != nil {
+		log.Warnf("Failed to set file flag required: %v", err)
+	}
+
+	return command
+}
+
+var (
+	limitsClause = regexp.MustCompile(`(?i)\bLIMITS\b`)
+	eventsFetch  = regexp.MustCompile(`(?i)\bevents\s*\(`)
+)
+
+// validationLimit is appended to events queries lacking a LIMITS clause so that validating them retrieves little data
+const validationLimit = "LIMITS events.count(1)"
+
+func validateQuery(flags *validateQueryFlags) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		query, err := readQuery(cmd, flags.file)
+		if err != nil {
+			return err
+		}
+		if eventsFetch.MatchString(query) && !limitsClause.MatchString(query) {
+			query = query + "\n" + validationLimit
+		}
+
+		resp, err := uql.ClientV1.ExecuteQuery(&uql.Query{Str: query})
+		if err != nil {
+			return fmt.Errorf("uql.ClientV1.ExecuteQuery: %w", err)
+		}
+		if !resp.HasErrors() {
+			output.PrintCmdStatus(cmd, "Query is valid\n")
+			return nil
+		}
+
+		lines := make([][]string, 0, len(resp.Errors()))
+		for _, e := range resp.Errors() {
+			lines = append(lines, []string{e.Type, e.Title, e.Detail})
+		}
+		output.PrintCmdOutputCustom(cmd, struct {
+			Items []*uql.Error `json:"items"`
+			Total int          `json:"total"`
+		}{Items: resp.Errors(), Total: len(resp.Errors())}, &output.Table{
+			Headers: []string{"Type", "Title", "Detail"},
+			Lines:   lines,
+		})
+		return fmt.Errorf("query has %v errors", len(resp.Errors()))
+	}
+}
+
+// readQuery reads the query from the file, or from the command's input for "-"
+func readQuery(cmd *cobra.Command, file string) (string, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read query: %w", err)
+	}
+	query := strings.TrimSpace(string(data))
+	if query == "" {
+		return "", errors.New("the query is empty")
+	}
+	return query, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/cmd/uql"
+)
+
+// queryWarning is an error reported within a UQL response, whose data may then be incomplete
+type queryWarning struct {
+	Query  string `json:"query" yaml:"query"`
+	Page   int    `json:"page,omitempty" yaml:"page,omitempty"`
+	Title  string `json:"title" yaml:"title"`
+	Detail string `json:"detail" yaml:"detail"`
+}
+
+// queryWarnings collects the errors reported within UQL responses so that machine-readable output can include them
+// as warnings. Follow cursors may be continued concurrently, hence the mutex
+type queryWarnings struct {
+	mu       sync.Mutex
+	warnings []queryWarning
+	strict   bool // fail on errors reported within responses rather than collect them, see --strict
+}
+
+// partialDataError is returned with --strict for a UQL response reporting errors, as its data may be incomplete
+type partialDataError struct {
+	query string
+	page  int
+	errs  []*uql.Error
+}
+
+func (e *partialDataError) Error() string {
+	details := make([]string, 0, len(e.errs))
+	for _, err := range e.errs {
+		details = append(details, fmt.Sprintf("%s: %s", err.Title, err.Detail))
+	}
+	where := e.query
+	if e.page > 0 {
+		where = fmt.Sprintf("page %v of %v", e.page, e.query)
+	}
+	return fmt.Sprintf("%v reported errors, its data may be incomplete (failing due to --strict): %v", where, strings.Join(details, "; "))
+}
+
+// addStrictFlag adds the --strict flag, turning the errors reported within UQL responses into a command failure
+func addStrictFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("strict", false, "Fail rather than output possibly incomplete data when UQL reports errors within a response, e.g., for pipelines that require complete data")
+}
+
+// setStrict applies the --strict flag of the command, if it has one
+func (w *queryWarnings) setStrict(cmd *cobra.Command) {
+	w.strict, _ = cmd.Flags().GetBool("strict")
+}
+
+// responseWarnings collects the warnings of the command being executed
+var responseWarnings = &queryWarnings{}
+
+// checkResponse logs and collects the errors reported within the response, action describing what produced it,
+// e.g., "Execution", and query naming the query, e.g., "events query". With --strict, the errors are returned as a
+// *partialDataError instead of being collected
+func (w *queryWarnings) checkResponse(resp *uql.Response, action string, query string) error {
+	if !resp.HasErrors() {
+		return nil
+	}
+	log.Errorf("%v of %v encountered errors. Returned data may not be complete!", action, query)
+	for _, e := range resp.Errors() {
+		log.Errorf("%s: %s", e.Title, e.Detail)
+	}
+	return w.report(query, 0, resp.Errors())
+}
+
+// onPageErrors returns a uql.Pages OnErrors function collecting the errors of continuation pages of the query,
+// which the iteration already logs, or ending the iteration with them with --strict
+func (w *queryWarnings) onPageErrors(query string) func(page int, errs []*uql.Error) error {
+	return func(page int, errs []*uql.Error) error {
+		return w.report(query, page, errs)
+	}
+}
+
+func (w *queryWarnings) report(query string, page int, errs []*uql.Error) error {
+	if w.strict {
+		return &partialDataError{query: query, page: page, errs: errs}
+	}
+	w.add(query, page, errs)
+	return nil
+}
+
+func (w *queryWarnings) add(query string, page int, errs []*uql.Error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, e := range errs {
+		w.warnings = append(w.warnings, queryWarning{Query: query, Page: page, Title: e.Title, Detail: e.Detail})
+	}
+}
+
+// drain returns the warnings collected since the last drain, so that each output reports its own
+func (w *queryWarnings) drain() []queryWarning {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	warnings := w.warnings
+	w.warnings = nil
+	return warnings
+}
@@ -0,0 +1,203 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeNow is the clock against which windows and calendar anchors are resolved, replaceable for tests
+var timeNow = time.Now
+
+// windowPresets lists the names accepted by --window, see resolveWindow for their boundaries
+var windowPresets = []string{"today", "yesterday", "last-24h", "last-7d", "last-30d"}
+
+// resolveWindow maps a named time window preset to RFC3339 since and until boundaries computed against the local
+// clock. An empty until means now.
+//
+//	today      local midnight today until now
+//	yesterday  local midnight yesterday until local midnight today
+//	last-24h   24 hours ago until now
+//	last-7d    7 days ago until now
+//	last-30d   30 days ago until now
+func resolveWindow(name string, now time.Time) (since string, until string, err error) {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch name {
+	case "today":
+		return midnight.Format(time.RFC3339), "", nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1).Format(time.RFC3339), midnight.Format(time.RFC3339), nil
+	case "last-24h":
+		return now.Add(-24 * time.Hour).Format(time.RFC3339), "", nil
+	case "last-7d":
+		return now.AddDate(0, 0, -7).Format(time.RFC3339), "", nil
+	case "last-30d":
+		return now.AddDate(0, 0, -30).Format(time.RFC3339), "", nil
+	}
+	return "", "", fmt.Errorf("unknown window %q, must be one of: %v", name, strings.Join(windowPresets, ", "))
+}
+
+// clock returns the current time in the --timezone location, the local time zone if none was given
+func (flags *eventsFlags) clock() (time.Time, error) {
+	if flags.timezone == "" {
+		return timeNow(), nil
+	}
+	location, err := time.LoadLocation(flags.timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --timezone %q, expected an IANA time zone name such as Europe/Paris: %w", flags.timezone, err)
+	}
+	return timeNow().In(location), nil
+}
+
+// applyWindow replaces the since and until flags with the boundaries of the --window preset or of the --range, if
+// one was given. Otherwise, since and until given as calendar anchors or unix timestamps are converted to RFC3339
+func (flags *eventsFlags) applyWindow() error {
+	now, err := flags.clock()
+	if err != nil {
+		return err
+	}
+	if flags.timeRange != "" {
+		since, until, err := parseTimeRange("range", flags.timeRange, now)
+		if err != nil {
+			return err
+		}
+		flags.since, flags.until = since, until
+		return nil
+	}
+	if flags.window == "" {
+		if flags.since, err = convertTime("since", flags.since, now); err != nil {
+			return err
+		}
+		flags.until, err = convertTime("until", flags.until, now)
+		return err
+	}
+	since, until, err := resolveWindow(flags.window, now)
+	if err != nil {
+		return err
+	}
+	flags.since, flags.until = since, until
+	return nil
+}
+
+// calendarAnchors lists the values of --since and --until standing for a calendar boundary, see resolveAnchor
+var calendarAnchors = []string{"@day", "@week", "@month"}
+
+// resolveAnchor returns the calendar boundary named by the anchor, at midnight in the location of now, which is
+// the --timezone one. Weeks start on Monday, as in ISO 8601.
+//
+//	@day    midnight today
+//	@week   midnight on Monday of the current week, today if it is a Monday
+//	@month  midnight on the first day of the current month
+func resolveAnchor(anchor string, now time.Time) (time.Time, error) {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch anchor {
+	case "@day":
+		return midnight, nil
+	case "@week":
+		// time.Sunday is 0, counting the days since Monday from 0 to 6
+		return midnight.AddDate(0, 0, -(int(now.Weekday())+6)%7), nil
+	case "@month":
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()), nil
+	}
+	return time.Time{}, fmt.Errorf("unknown calendar anchor %q, must be one of: %v", anchor, strings.Join(calendarAnchors, ", "))
+}
+
+// convertTime converts a calendar anchor resolved against now or a unix timestamp to the RFC3339 form expected by
+// UQL, see resolveAnchor and convertUnixTimestamp. Other values are returned unchanged
+func convertTime(flagName string, value string, now time.Time) (string, error) {
+	if !strings.HasPrefix(value, "@") {
+		return convertUnixTimestamp(flagName, value)
+	}
+	t, err := resolveAnchor(value, now)
+	if err != nil {
+		return "", fmt.Errorf("invalid --%v: %w", flagName, err)
+	}
+	return t.Format(time.RFC3339), nil
+}
+
+// convertUnixTimestamp converts a value made of digits only to the RFC3339 form expected by UQL, interpreting it as
+// unix seconds if it has 10 digits or unix milliseconds if it has 13. Other values are returned unchanged
+func convertUnixTimestamp(flagName string, value string) (string, error) {
+	if value == "" || strings.TrimLeft(value, "0123456789") != "" {
+		return value, nil
+	}
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid --%v unix timestamp %q: %w", flagName, value, err)
+	}
+	switch len(value) {
+	case 10:
+		return time.Unix(number, 0).UTC().Format(time.RFC3339), nil
+	case 13:
+		return time.UnixMilli(number).UTC().Format(time.RFC3339Nano), nil
+	}
+	return "", fmt.Errorf("ambiguous --%v unix timestamp %q, expected 10 digits for seconds or 13 digits for milliseconds", flagName, value)
+}
+
+// parseTimeRange splits a value of the form since..until of the flag, where an empty until means now and each side
+// is a relative or exact time, a calendar anchor or a unix timestamp. When both sides can be resolved, since must precede until;
+// other forms are left to UQL to validate
+func parseTimeRange(flagName string, value string, now time.Time) (since string, until string, err error) {
+	since, until, found := strings.Cut(value, "..")
+	if !found || since == "" || strings.Contains(until, "..") {
+		return "", "", fmt.Errorf("invalid --%v %q, expected the form since..until, e.g., -7d..-1d", flagName, value)
+	}
+	if since, err = convertTime(flagName, since, now); err != nil {
+		return "", "", err
+	}
+	if until, err = convertTime(flagName, until, now); err != nil {
+		return "", "", err
+	}
+	untilTime, untilOk := now, true
+	if until != "" {
+		untilTime, untilOk = resolveTime(until, now)
+	}
+	if sinceTime, sinceOk := resolveTime(since, now); sinceOk && untilOk && !sinceTime.Before(untilTime) {
+		return "", "", fmt.Errorf("invalid --%v %q, the start must precede the end", flagName, value)
+	}
+	return since, until, nil
+}
+
+// relativeTimeUnits are the units of relative times understood by resolveTime; UQL accepts a few others
+var relativeTimeUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// resolveTime resolves an RFC3339 time or a relative time such as -7d against now. ok is false for other forms
+func resolveTime(value string, now time.Time) (t time.Time, ok bool) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, true
+	}
+	if value == "now" {
+		return now, true
+	}
+	number := strings.TrimRight(value, "smhdw")
+	unit, ok := relativeTimeUnits[value[len(number):]]
+	if !ok {
+		return time.Time{}, false
+	}
+	count, err := strconv.Atoi(number)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return now.Add(time.Duration(count) * unit), true
+}
@@ -0,0 +1,180 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/apex/log"
+	"github.com/spf13/cobra"
+
+	"github.com/cisco-open/fsoc/cmd/uql"
+	"github.com/cisco-open/fsoc/cmdkit/term"
+	"github.com/cisco-open/fsoc/output"
+)
+
+// windowComparison is the count of an event type in the queried window and in the --compare-with window
+type windowComparison struct {
+	EventType     string
+	Count         int
+	ComparedCount int
+	Delta         int
+}
+
+// compareWindows outputs the per event type counts of the query window next to those of the --compare-with window
+func (flags *eventsCmdFlags) compareWindows(cmd *cobra.Command, queryVals eventsQueryValues) error {
+	comparedVals := queryVals
+	now, err := flags.clock()
+	if err != nil {
+		return err
+	}
+	if comparedVals.Since, comparedVals.Until, err = parseTimeRange("compare-with", flags.compareWith, now); err != nil {
+		return err
+	}
+
+	counts, err := flags.countEventTypesInWindow(queryVals)
+	if err != nil {
+		return err
+	}
+	comparedCounts, err := flags.countEventTypesInWindow(comparedVals)
+	if err != nil {
+		return err
+	}
+	comparisons := compareEventTypeCounts(counts, comparedCounts)
+	if flags.failOnEmpty && len(comparisons) < 1 {
+		return errNoResults
+	}
+
+	window, comparedWindow := describeWindow(queryVals.Since, queryVals.Until), describeWindow(comparedVals.Since, comparedVals.Until)
+	colored := term.AllowsColorOutput(cmd.OutOrStdout())
+	lines := make([][]string, 0, len(comparisons))
+	for _, comparison := range comparisons {
+		lines = append(lines, []string{comparison.EventType, strconv.Itoa(comparison.Count), strconv.Itoa(comparison.ComparedCount), formatDelta(comparison.Delta, colored)})
+	}
+	output.PrintCmdOutputCustom(cmd, struct {
+		Window         string             `json:"window"`
+		ComparedWindow string             `json:"comparedWindow" yaml:"comparedWindow"`
+		Items          []windowComparison `json:"items"`
+		Total          int                `json:"total"`
+		Warnings       []queryWarning     `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+	}{Window: window, ComparedWindow: comparedWindow, Items: comparisons, Total: len(comparisons), Warnings: responseWarnings.drain()}, &output.Table{
+		Headers: []string{"EventType", window, comparedWindow, "Delta"},
+		Lines:   lines,
+	})
+	return nil
+}
+
+// countEventTypesInWindow counts the events of the query per event type, aggregated by UQL unless client-side
+// filters apply, in which case the events are retrieved and counted
+func (flags *eventsCmdFlags) countEventTypesInWindow(queryVals eventsQueryValues) ([]eventTypeCount, error) {
+	if !flags.filtersAttributes() && !flags.filtersProgress() {
+		counts, err := queryEventTypeCounts(queryVals)
+		if err == nil {
+			return counts, nil
+		}
+		log.Warnf("Aggregation of event counts by UQL failed, counting retrieved events instead: %v", err)
+	}
+
+	resp, err := uql.ClientV1.ExecuteQuery(eventsQuery(queryVals))
+	if err != nil {
+		return nil, fmt.Errorf("uql.ClientV1.ExecuteQuery: %w", err)
+	}
+	if err := responseWarnings.checkResponse(resp, "Execution", "events query"); err != nil {
+		return nil, err
+	}
+	main_data_set := resp.Main()
+	if main_data_set == nil || len(main_data_set.Data) < 1 {
+		return []eventTypeCount{}, nil
+	}
+	rows := []EventsRow{}
+	pages := uql.Pages{Nested: true, Continue: flags.retries.continueQuery, Description: "events query", OnErrors: responseWarnings.onPageErrors("events query")}
+	_, err = pages.Iterate(resp, func(page int, dataSet *uql.DataSet) (bool, error) {
+		newRows, err := extractEventsData(dataSet)
+		if err != nil {
+			return false, fmt.Errorf("page %v extractEventsData: %w", page, err)
+		}
+		rows = append(rows, newRows...)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return countEventTypes(flags.filterByProgress(flags.filterByAttributePresence(rows))), nil
+}
+
+// compareEventTypeCounts pairs the counts of both windows by event type, ordering the largest changes first
+func compareEventTypeCounts(counts []eventTypeCount, comparedCounts []eventTypeCount) []windowComparison {
+	byType := make(map[string]*windowComparison)
+	for _, count := range counts {
+		byType[count.EventType] = &windowComparison{EventType: count.EventType, Count: count.Count}
+	}
+	for _, count := range comparedCounts {
+		comparison, ok := byType[count.EventType]
+		if !ok {
+			comparison = &windowComparison{EventType: count.EventType}
+			byType[count.EventType] = comparison
+		}
+		comparison.ComparedCount = count.Count
+	}
+	comparisons := make([]windowComparison, 0, len(byType))
+	for _, comparison := range byType {
+		comparison.Delta = comparison.Count - comparison.ComparedCount
+		comparisons = append(comparisons, *comparison)
+	}
+	sort.Slice(comparisons, func(i, j int) bool {
+		left, right := abs(comparisons[i].Delta), abs(comparisons[j].Delta)
+		if left != right {
+			return left > right
+		}
+		return comparisons[i].EventType < comparisons[j].EventType
+	})
+	return comparisons
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+func describeWindow(since string, until string) string {
+	if since == "" {
+		since = "-1h"
+	}
+	if until == "" {
+		until = "now"
+	}
+	return fmt.Sprintf("%v..%v", since, until)
+}
+
+// formatDelta signs the delta and marks increases and decreases, in green and red on a color terminal
+func formatDelta(delta int, colored bool) string {
+	switch {
+	case delta > 0:
+		if colored {
+			return fmt.Sprintf("\033[32m+%v ▲%v", delta, colorReset)
+		}
+		return fmt.Sprintf("+%v ▲", delta)
+	case delta < 0:
+		if colored {
+			return fmt.Sprintf("\033[31m%v ▼%v", delta, colorReset)
+		}
+		return fmt.Sprintf("%v ▼", delta)
+	}
+	return "0"
+}
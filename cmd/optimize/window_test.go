@@ -0,0 +1,88 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimize
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveTime(t *testing.T) {
+	now := time.Date(2023, time.August, 16, 14, 30, 0, 0, time.UTC)
+	tests := []struct {
+		value    string
+		expected time.Time
+		ok       bool
+	}{
+		{value: "2023-08-01T10:00:00Z", expected: time.Date(2023, time.August, 1, 10, 0, 0, 0, time.UTC), ok: true},
+		{value: "2023-08-01T10:00:00.5+02:00", expected: time.Date(2023, time.August, 1, 8, 0, 0, 5e8, time.UTC), ok: true},
+		{value: "now", expected: now, ok: true},
+		{value: "-30s", expected: now.Add(-30 * time.Second), ok: true},
+		{value: "-15m", expected: now.Add(-15 * time.Minute), ok: true},
+		{value: "-2h", expected: now.Add(-2 * time.Hour), ok: true},
+		{value: "-7d", expected: now.AddDate(0, 0, -7), ok: true},
+		{value: "-1w", expected: now.AddDate(0, 0, -7), ok: true},
+		{value: "1d", expected: now.AddDate(0, 0, 1), ok: true},
+		{value: "-1y"},
+		{value: "-d"},
+		{value: "-1dd"},
+		{value: "1692000000"},
+		{value: ""},
+	}
+	for _, test := range tests {
+		actual, ok := resolveTime(test.value, now)
+		assert.Equal(t, test.ok, ok, test.value)
+		if test.ok {
+			assert.True(t, test.expected.Equal(actual), "%v: expected %v, got %v", test.value, test.expected, actual)
+		}
+	}
+}
+
+func TestParseTimeRange(t *testing.T) {
+	now := time.Date(2023, time.August, 16, 14, 30, 0, 0, time.UTC)
+	tests := []struct {
+		value    string
+		since    string
+		until    string
+		expected string
+	}{
+		{value: "-7d..-1d", since: "-7d", until: "-1d"},
+		{value: "-2h..", since: "-2h", until: ""},
+		{value: "@month..@week", since: "2023-08-01T00:00:00Z", until: "2023-08-14T00:00:00Z"},
+		{value: "1692000000..1692100000000", since: "2023-08-14T08:00:00Z", until: "2023-08-15T11:46:40Z"},
+		{value: "-7d..2023-08-16T00:00:00Z", since: "-7d", until: "2023-08-16T00:00:00Z"},
+		{value: "-1mo..-1d", since: "-1mo", until: "-1d"},
+		{value: "-1d..-7d", expected: "the start must precede the end"},
+		{value: "1d..", expected: "the start must precede the end"},
+		{value: "-7d", expected: "expected the form since..until"},
+		{value: "..-1d", expected: "expected the form since..until"},
+		{value: "-7d..-2d..-1d", expected: "expected the form since..until"},
+		{value: "@year..", expected: "unknown calendar anchor"},
+		{value: "169200000..", expected: "ambiguous --range unix timestamp"},
+	}
+	for _, test := range tests {
+		since, until, err := parseTimeRange("range", test.value, now)
+		if test.expected != "" {
+			assert.ErrorContains(t, err, test.expected, test.value)
+			continue
+		}
+		require.NoError(t, err, test.value)
+		assert.Equal(t, test.since, since, test.value)
+		assert.Equal(t, test.until, until, test.value)
+	}
+}
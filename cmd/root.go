@@ -32,9 +32,11 @@ import (
 	"github.com/spf13/viper"
 	"golang.org/x/exp/maps"
 
+	"github.com/cisco-open/fsoc/cmd/uql"
 	"github.com/cisco-open/fsoc/cmd/version"
 	"github.com/cisco-open/fsoc/config"
 	"github.com/cisco-open/fsoc/logfilter"
+	"github.com/cisco-open/fsoc/output"
 	"github.com/cisco-open/fsoc/platform/api"
 )
 
@@ -42,6 +44,9 @@ var cfgFile string
 var cfgProfile string
 var outputFormat string
 
+// outputFile is the --output-file the command output is written to, closed by Execute
+var outputFile *os.File
+
 const FSOC_NO_VERSION_CHECK = "FSOC_NO_VERSION_CHECK"
 
 const (
@@ -89,7 +94,32 @@ NOTE: fsoc is in alpha; breaking changes may occur`,
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute(ctx context.Context) error {
-	return rootCmd.ExecuteContext(ctx)
+	err := rootCmd.ExecuteContext(ctx)
+	if closeErr := closeOutputFile(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// closeOutputFile flushes the --output-file to disk and closes it, reporting a failure to write it. Devices such
+// as /dev/stdout cannot be synced and are only closed
+func closeOutputFile() error {
+	if outputFile == nil {
+		return nil
+	}
+	file := outputFile
+	outputFile = nil
+	var syncErr error
+	if info, err := file.Stat(); err != nil || info.Mode().IsRegular() {
+		syncErr = file.Sync()
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close output file %v: %w", file.Name(), err)
+	}
+	if syncErr != nil {
+		return fmt.Errorf("failed to write output file %v: %w", file.Name(), syncErr)
+	}
+	return nil
 }
 
 func init() {
@@ -101,10 +131,21 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", fmt.Sprintf("config file (default is %s). May be .yaml or .json", config.DefaultConfigFile))
 	rootCmd.PersistentFlags().StringVar(&cfgProfile, "profile", "", "access profile (default is current or \"default\")")
-	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "auto", "output format (auto, table, detail, json, yaml)")
-	rootCmd.PersistentFlags().String("fields", "", "perform specified fields transform/extract JQ expression")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "auto", "output format (auto, table, detail, json, json-compact, yaml, html, markdown)")
+	rootCmd.PersistentFlags().String("output-file", "", "write the command output to the given file rather than to stdout, e.g., report.html")
+	rootCmd.PersistentFlags().String("fields", "", "perform specified fields transform/extract JQ expression (ignored for json and json-compact output)")
+	rootCmd.PersistentFlags().Int("max-col-width", 0, "truncate table cells beyond the given number of characters with an ellipsis (default: no limit)")
+	rootCmd.PersistentFlags().Bool("wrap", false, "wrap table cells beyond --max-col-width instead of truncating them")
+	rootCmd.PersistentFlags().Bool("transpose", false, "print table output vertically, one field per line and a blank line between records")
+	rootCmd.PersistentFlags().String("jq", "", "transform the json or json-compact output with a jq expression, e.g., '.items | map(.Timestamp)'")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Enable detailed output")
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "Only log errors to the terminal, keeping status messages off the standard output")
+	rootCmd.MarkFlagsMutuallyExclusive("verbose", "quiet")
 	rootCmd.PersistentFlags().Bool("curl", false, "Log curl equivalent for platform API calls (implies --verbose)")
+	rootCmd.PersistentFlags().Bool("trace", false, "Dump the raw UQL requests and responses to stderr, with credentials redacted")
+	rootCmd.PersistentFlags().StringSlice("header", nil, "Add a header to the UQL requests, in the form name=value. May be repeated; authentication headers cannot be overridden")
+	rootCmd.PersistentFlags().String("tenant", "", "Issue the UQL requests against the given tenant ID rather than the tenant of the current context")
+	rootCmd.PersistentFlags().Float64("rps", uql.DefaultRequestsPerSecond, "Maximum rate of UQL requests per second, shared by concurrent queries; 0 disables the limit")
 	rootCmd.PersistentFlags().String("log", path.Join(os.TempDir(), "fsoc.log"), "determines the location of the fsoc log file")
 	rootCmd.PersistentFlags().Bool("no-version-check", false, "Skip the daily check for new versions of fsoc")
 	rootCmd.SetOut(os.Stdout)
@@ -186,8 +227,44 @@ func preExecHook(cmd *cobra.Command, args []string) {
 		api.FlagCurlifyRequests = true
 		verbose = true // force verbose
 	}
+	uql.FlagTraceRequests, _ = cmd.Flags().GetBool("trace")
+	if width, _ := cmd.Flags().GetInt("max-col-width"); width < 0 {
+		log.Fatalf("Invalid --max-col-width %v, the width must not be negative", width)
+	} else if wrap, _ := cmd.Flags().GetBool("wrap"); wrap && width == 0 {
+		log.Fatalf("--wrap requires --max-col-width to wrap the table cells at")
+	}
+	if jq, _ := cmd.Flags().GetString("jq"); jq != "" {
+		if _, err := output.CompileJq(jq); err != nil {
+			log.Fatalf("%v", err)
+		}
+		if format, _ := cmd.Flags().GetString("output"); format != "json" && format != "json-compact" {
+			log.Warnf("--jq is only applied to the json and json-compact output formats, ignoring it for %q", format)
+		}
+	}
+	if path, _ := cmd.Flags().GetString("output-file"); path != "" {
+		// output is written to the file unbuffered, Execute closes it once the command completed
+		file, err := os.Create(path)
+		if err != nil {
+			log.Fatalf("Failed to create output file: %v", err)
+		}
+		outputFile = file
+		cmd.Root().SetOut(file)
+	}
+	headers, _ := cmd.Flags().GetStringSlice("header")
+	tenant, _ := cmd.InheritedFlags().GetString("tenant") // not the deprecated local flag of config set
+	if err := uql.SetRequestHeaders(headers, tenant); err != nil {
+		log.Fatalf("Invalid UQL request headers: %v", err)
+	}
+	if rps, _ := cmd.Flags().GetFloat64("rps"); rps >= 0 {
+		uql.SetRequestRate(rps)
+	} else {
+		log.Fatalf("Invalid --rps %v, the rate of UQL requests must not be negative", rps)
+	}
+	quiet, _ := cmd.Flags().GetBool("quiet")
 	if verbose {
 		cliHandler = logfilter.New(os.Stderr, log.InfoLevel)
+	} else if quiet {
+		cliHandler = logfilter.New(os.Stderr, log.ErrorLevel)
 	} else {
 		cliHandler = logfilter.New(os.Stderr, log.WarnLevel)
 	}
@@ -24,20 +24,27 @@ import (
 	"github.com/cisco-open/fsoc/platform/api"
 )
 
+// FlagTraceRequests is set by the global --trace flag to dump the raw UQL requests and responses to stderr
+var FlagTraceRequests bool
+
 type defaultBackend struct {
 	apiOptions *api.Options
 }
 
 func (b defaultBackend) Execute(query *Query, apiVersion ApiVersion) (parsedResponse, error) {
 	log.WithFields(log.Fields{"query": query.Str, "apiVersion": apiVersion}).Info("executing UQL query")
+	requestLimiter.wait()
 
 	var rawJson json.RawMessage
-	err := api.JSONPost(GetAPIEndpoint(apiVersion), query, &rawJson, b.apiOptions)
+	options := b.callOptions()
+	err := api.JSONPost(GetAPIEndpoint(apiVersion), query, &rawJson, options)
 	if err != nil {
 		if problem, ok := err.(api.Problem); ok {
-			return parsedResponse{}, makeUqlProblem(problem)
+			uqlProblem := makeUqlProblem(problem)
+			uqlProblem.requestId = requestIdOf(options.ResponseHeaders)
+			return parsedResponse{}, makeAuthError(uqlProblem, options.ResponseStatus)
 		}
-		return parsedResponse{}, errors.Wrap(err, fmt.Sprintf("failed to execute UQL Query: '%s'", query.Str))
+		return parsedResponse{}, makeRequestError(errors.Wrap(err, fmt.Sprintf("failed to execute UQL Query: '%s'", query.Str)), options)
 	}
 	var chunks []parsedChunk
 	err = json.Unmarshal(rawJson, &chunks)
@@ -52,11 +59,13 @@ func (b defaultBackend) Execute(query *Query, apiVersion ApiVersion) (parsedResp
 
 func (b defaultBackend) Continue(link *Link) (parsedResponse, error) {
 	log.WithFields(log.Fields{"query": link.Href}).Info("continuing UQL query")
+	requestLimiter.wait()
 
 	var rawJson json.RawMessage
-	err := api.JSONGet(link.Href, &rawJson, b.apiOptions)
+	options := b.callOptions()
+	err := api.JSONGet(link.Href, &rawJson, options)
 	if err != nil {
-		return parsedResponse{}, errors.Wrap(err, fmt.Sprintf("failed follow link: '%s'", link.Href))
+		return parsedResponse{}, makeRequestError(errors.Wrap(err, fmt.Sprintf("failed follow link: '%s'", link.Href)), options)
 	}
 	var chunks []parsedChunk
 	err = json.Unmarshal(rawJson, &chunks)
@@ -69,6 +78,18 @@ func (b defaultBackend) Continue(link *Link) (parsedResponse, error) {
 	}, nil
 }
 
+// callOptions returns a copy of the backend's API options so that the response status and headers
+// of each call can be inspected without sharing them across calls. The global request headers are added
+func (b defaultBackend) callOptions() *api.Options {
+	options := api.Options{}
+	if b.apiOptions != nil {
+		options = *b.apiOptions
+	}
+	options.Trace = options.Trace || FlagTraceRequests
+	options.Headers = withRequestHeaders(options.Headers)
+	return &options
+}
+
 func NewDefaultBackend(options ...BackendOption) defaultBackend {
 	b := defaultBackend{}
 	for _, option := range options {
@@ -0,0 +1,195 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Builder assembles a UQL query clause by clause, e.g.,
+//
+//	uql.NewBuilder().Since("-1h").Fetch("events", "optimize:optimization_started").
+//		Where(uql.AttributeEquals("k8s.cluster.id", clusterId)).Fields("attributes", "timestamp").
+//		OrderAsc("events").Build()
+//
+// Values compared in conditions should be rendered with QuoteString (or the Attribute* helpers) so that they
+// are escaped properly
+type Builder struct {
+	since      string
+	until      string
+	fetch      string
+	fetchTypes []string
+	fields     []string
+	from       string
+	conditions []string
+	limits     []string
+	order      []string
+}
+
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Since sets the start of the time interval, as a relative or exact time. An empty value is ignored
+func (b *Builder) Since(since string) *Builder {
+	b.since = since
+	return b
+}
+
+// Until sets the end of the time interval, as a relative or exact time. An empty value is ignored
+func (b *Builder) Until(until string) *Builder {
+	b.until = until
+	return b
+}
+
+// Fetch sets what is fetched. If types are given, source is a function of them, e.g., events(type1, type2)
+func (b *Builder) Fetch(source string, types ...string) *Builder {
+	b.fetch = source
+	b.fetchTypes = types
+	return b
+}
+
+// Fields sets the fields projected from the fetched source, e.g., {attributes, timestamp}
+func (b *Builder) Fields(fields ...string) *Builder {
+	b.fields = fields
+	return b
+}
+
+// From sets the entities the fetch is applied to, e.g., entities(k8s:workload)
+func (b *Builder) From(from string) *Builder {
+	b.from = from
+	return b
+}
+
+// Where adds a filter condition; all conditions must hold. The filter applies to the From clause if set,
+// otherwise to the fetched source. Empty conditions are ignored
+func (b *Builder) Where(conditions ...string) *Builder {
+	for _, condition := range conditions {
+		if condition != "" {
+			b.conditions = append(b.conditions, condition)
+		}
+	}
+	return b
+}
+
+// Limit limits the count of the given field, e.g., events.count(10)
+func (b *Builder) Limit(field string, count int) *Builder {
+	b.limits = append(b.limits, fmt.Sprintf("%v.count(%d)", field, count))
+	return b
+}
+
+// OrderAsc orders the given field in ascending order
+func (b *Builder) OrderAsc(field string) *Builder {
+	b.order = append(b.order, field+".asc()")
+	return b
+}
+
+// OrderDesc orders the given field in descending order
+func (b *Builder) OrderDesc(field string) *Builder {
+	b.order = append(b.order, field+".desc()")
+	return b
+}
+
+// String returns the query text
+func (b *Builder) String() string {
+	var sb strings.Builder
+	if b.since != "" {
+		sb.WriteString("SINCE " + b.since + "\n")
+	}
+	if b.until != "" {
+		sb.WriteString("UNTIL " + b.until + "\n")
+	}
+
+	filter := ""
+	if len(b.conditions) > 0 {
+		filter = "[" + strings.Join(b.conditions, " && ") + "]"
+	}
+
+	sb.WriteString("FETCH " + b.fetch)
+	if len(b.fetchTypes) > 0 {
+		sb.WriteString("(\n\t\t" + strings.Join(b.fetchTypes, ",\n\t\t") + "\n\t)")
+	}
+	if b.from == "" && filter != "" {
+		sb.WriteString("\n\t" + filter)
+	}
+	if len(b.fields) > 0 {
+		sb.WriteString("\n\t{" + strings.Join(b.fields, ", ") + "}")
+	}
+	sb.WriteString("\n")
+	if b.from != "" {
+		sb.WriteString("FROM " + b.from + filter + "\n")
+	}
+
+	if len(b.limits) > 0 {
+		sb.WriteString("LIMITS " + strings.Join(b.limits, ", ") + "\n")
+	}
+	if len(b.order) > 0 {
+		sb.WriteString("ORDER " + strings.Join(b.order, ", ") + "\n")
+	}
+	return sb.String()
+}
+
+// Build returns the query
+func (b *Builder) Build() *Query {
+	return &Query{Str: b.String()}
+}
+
+// QuoteString renders the value as a double-quoted UQL string literal, see EscapeString
+func QuoteString(value string) string {
+	return `"` + EscapeString(value) + `"`
+}
+
+// EscapeString escapes the value for use within a double-quoted UQL string literal. Backslashes, quotes and
+// control characters are escaped; other characters, including non-ASCII Unicode, are kept as they are.
+// Unlike Go's %q, it never produces \x or Go-specific escapes that the UQL string grammar does not accept
+func EscapeString(value string) string {
+	var sb strings.Builder
+	for _, r := range value {
+		switch r {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '"':
+			sb.WriteString(`\"`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&sb, `\u%04x`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// AttributeEquals renders a condition matching the attribute to the value
+func AttributeEquals(attribute string, value string) string {
+	return fmt.Sprintf("attributes(%v) = %v", attribute, QuoteString(value))
+}
+
+// AttributeIn renders a condition matching the attribute to any of the values
+func AttributeIn(attribute string, values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, QuoteString(value))
+	}
+	return fmt.Sprintf("attributes(%v) IN [%v]", attribute, strings.Join(quoted, ", "))
+}
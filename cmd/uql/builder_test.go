@@ -0,0 +1,91 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilderEvents(t *testing.T) {
+	query := NewBuilder().
+		Since("-1h").
+		Fetch("events", "optimize:optimization_started", "optimize:optimization_ended").
+		Where(AttributeEquals("k8s.cluster.id", "abc"), "").
+		Where(AttributeIn("optimize.optimization.optimizer_id", []string{"one", "two"})).
+		Fields("attributes", "timestamp").
+		Limit("events", 10).
+		OrderAsc("events").
+		Build()
+
+	expected := `SINCE -1h
+FETCH events(
+		optimize:optimization_started,
+		optimize:optimization_ended
+	)
+	[attributes(k8s.cluster.id) = "abc" && attributes(optimize.optimization.optimizer_id) IN ["one", "two"]]
+	{attributes, timestamp}
+LIMITS events.count(10)
+ORDER events.asc()
+`
+	assert.Equal(t, expected, query.Str)
+}
+
+func TestBuilderEntities(t *testing.T) {
+	query := NewBuilder().
+		Fetch("id").
+		From("entities(k8s:workload)").
+		Where(AttributeEquals("k8s.workload.name", "frontend")).
+		Build()
+
+	assert.Equal(t, "FETCH id\nFROM entities(k8s:workload)[attributes(k8s.workload.name) = \"frontend\"]\n", query.Str)
+}
+
+func TestQuoteString(t *testing.T) {
+	assert.Equal(t, `"plain"`, QuoteString("plain"))
+	assert.Equal(t, `"with \"quotes\" and \\ backslash"`, QuoteString(`with "quotes" and \ backslash`))
+	assert.Equal(t, `"line\nbreak"`, QuoteString("line\nbreak"))
+}
+
+func TestEscapeString(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"quotes", `say "hi"`, `say \"hi\"`},
+		{"backslashes", `C:\dir\`, `C:\\dir\\`},
+		{"escaped quote", `\"`, `\\\"`},
+		{"unicode", "ns-日本語-ü-🚀", "ns-日本語-ü-🚀"},
+		{"control characters", "a\tb\x00c\x1fd", `a\tb\u0000c\u001fd`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, EscapeString(test.value))
+		})
+	}
+}
+
+func TestAttributeEqualsSpecialCharacters(t *testing.T) {
+	query := NewBuilder().
+		Fetch("events", "optimize:recommendation_verified").
+		Where(AttributeEquals("k8s.namespace.name", `team "a" \ prod`)).
+		Where(AttributeIn("optimize.optimization.optimizer_id", []string{`x"]] || true`, "ü"})).
+		Build()
+
+	assert.Contains(t, query.Str, `attributes(k8s.namespace.name) = "team \"a\" \\ prod"`)
+	assert.Contains(t, query.Str, `attributes(optimize.optimization.optimizer_id) IN ["x\"]] || true", "ü"]`)
+}
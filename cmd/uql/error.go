@@ -15,7 +15,11 @@
 package uql
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"strconv"
 	"strings"
 
@@ -28,11 +32,135 @@ type uqlProblem struct {
 	query        string
 	title        string
 	detail       string
+	status       int
+	requestId    string
 	errorDetails []errorDetail
 }
 
 func (p uqlProblem) Error() string {
-	return fmt.Sprintf("%s: %s", p.title, p.detail)
+	return fmt.Sprintf("%s%s: %s", describeRequest(p.status, p.requestId), p.title, p.detail)
+}
+
+// requestIdHeaders are the response headers checked, in order, for an identifier of the failed request
+// that platform support can correlate with server side logs
+var requestIdHeaders = []string{"X-Request-Id", "X-Trace-Id", "Traceparent"}
+
+// requestError annotates a failed UQL request with the HTTP status code and request ID reported by the server
+type requestError struct {
+	status    int
+	requestId string
+	err       error
+}
+
+func makeRequestError(err error, options *api.Options) error {
+	if options.ResponseStatus == 0 {
+		// no response was received, e.g., a network failure
+		return err
+	}
+	return makeAuthError(requestError{
+		status:    options.ResponseStatus,
+		requestId: requestIdOf(options.ResponseHeaders),
+		err:       err,
+	}, options.ResponseStatus)
+}
+
+func (e requestError) Error() string {
+	return fmt.Sprintf("%s%v", describeRequest(e.status, e.requestId), e.err)
+}
+
+func (e requestError) Unwrap() error {
+	return e.err
+}
+
+// AuthError is returned for UQL requests rejected by the platform as unauthenticated (HTTP 401) or forbidden
+// (HTTP 403), even after the access token was refreshed, so that callers can tell the problem is the credentials
+// rather than the query
+type AuthError struct {
+	Status int
+	err    error
+}
+
+// makeAuthError returns the error of a request as an AuthError if the response status denies access
+func makeAuthError(err error, status int) error {
+	if status != http.StatusUnauthorized && status != http.StatusForbidden {
+		return err
+	}
+	return AuthError{Status: status, err: err}
+}
+
+func (e AuthError) Error() string {
+	return fmt.Sprintf("%v; the access token may have expired or the context may not have access to the tenant, use \"fsoc login\" to log in again or \"fsoc config get\" to check the context", e.err)
+}
+
+func (e AuthError) Unwrap() error {
+	return e.err
+}
+
+// transientStatuses are the HTTP statuses of UQL requests that may succeed if retried later
+var transientStatuses = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// IsTransient reports whether a failed UQL request may succeed if retried: the request or its response was cut
+// short by a network failure, or the platform responded with one of transientStatuses. Authentication failures,
+// other statuses, e.g., of an invalid or expired cursor, and errors such as a missing link or an unparseable
+// response are not transient
+func IsTransient(err error) bool {
+	if authErr := (AuthError{}); errors.As(err, &authErr) {
+		return false
+	}
+	if reqErr := (requestError{}); errors.As(err, &reqErr) {
+		return isTransientStatus(reqErr.status) || isNetworkFailure(reqErr.err)
+	}
+	if problem := (uqlProblem{}); errors.As(err, &problem) {
+		return isTransientStatus(problem.status)
+	}
+	return isNetworkFailure(err)
+}
+
+func isTransientStatus(status int) bool {
+	for _, transient := range transientStatuses {
+		if status == transient {
+			return true
+		}
+	}
+	return false
+}
+
+// isNetworkFailure reports whether the error is that of a connection, e.g., refused, reset or timed out
+func isNetworkFailure(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// describeRequest formats the status and request ID as an error message prefix, e.g.,
+// "UQL query failed: HTTP 400 (request-id abc123): ". Returns an empty string if neither is known
+func describeRequest(status int, requestId string) string {
+	if status == 0 && requestId == "" {
+		return ""
+	}
+	s := "UQL query failed:"
+	if status != 0 {
+		s += fmt.Sprintf(" HTTP %d", status)
+	}
+	if requestId != "" {
+		s += fmt.Sprintf(" (request-id %s)", requestId)
+	}
+	return s + ": "
+}
+
+func requestIdOf(headers map[string][]string) string {
+	for _, name := range requestIdHeaders {
+		if values := http.Header(headers).Values(name); len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+	}
+	return ""
 }
 
 // errorDetail contains detailed information about user error in the query
@@ -57,6 +185,7 @@ func makeUqlProblem(original api.Problem) uqlProblem {
 		query:        asStringOrNothing(original.Extensions["query"]),
 		title:        original.Title,
 		detail:       original.Detail,
+		status:       original.Status,
 		errorDetails: make([]errorDetail, 0),
 	}
 	switch array := original.Extensions["errorDetails"].(type) {
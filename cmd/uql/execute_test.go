@@ -17,6 +17,10 @@ package uql
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"testing"
 	"text/template"
 	"time"
@@ -467,6 +471,57 @@ func TestMakeUqlProblem(t *testing.T) {
 	check.EqualValues(expected, problem, "uqlProblem struct should be correctly mapped from source data")
 }
 
+func TestMakeRequestError(t *testing.T) {
+	check := assert.New(t)
+	cause := errors.New("error response: bad things")
+
+	err := makeRequestError(cause, &api.Options{
+		ResponseStatus:  400,
+		ResponseHeaders: map[string][]string{"X-Request-Id": {"abc123"}},
+	})
+	check.Equal("UQL query failed: HTTP 400 (request-id abc123): error response: bad things", err.Error())
+	check.ErrorIs(err, cause)
+
+	err = makeRequestError(cause, &api.Options{})
+	check.Equal(cause, err, "errors without a response should not be annotated")
+}
+
+func TestMakeRequestError_Auth(t *testing.T) {
+	check := assert.New(t)
+	cause := errors.New("error response: unauthorized")
+
+	err := makeRequestError(cause, &api.Options{ResponseStatus: 401})
+	var authErr AuthError
+	check.ErrorAs(err, &authErr)
+	check.Equal(401, authErr.Status)
+	check.ErrorIs(err, cause)
+	check.Contains(err.Error(), "UQL query failed: HTTP 401: error response: unauthorized; the access token may have expired")
+	check.Contains(err.Error(), "fsoc login")
+
+	err = makeAuthError(uqlProblem{title: "Forbidden", status: 403}, 403)
+	check.ErrorAs(err, &authErr)
+	check.Equal(403, authErr.Status)
+
+	err = makeRequestError(cause, &api.Options{ResponseStatus: 500})
+	check.False(errors.As(err, &authErr), "other statuses should not be reported as authentication errors")
+}
+
+func TestIsTransient(t *testing.T) {
+	check := assert.New(t)
+	refused := fmt.Errorf("GET request to %q failed: %w", "https://example.com", &net.OpError{Op: "dial", Err: errors.New("connection refused")})
+
+	check.True(IsTransient(refused), "network failures without a response should be transient")
+	check.True(IsTransient(fmt.Errorf("follow: %w", makeRequestError(errors.New("unavailable"), &api.Options{ResponseStatus: 503}))))
+	check.True(IsTransient(makeRequestError(errors.New("slow down"), &api.Options{ResponseStatus: 429})))
+	check.True(IsTransient(makeRequestError(fmt.Errorf("failed reading response: %w", io.ErrUnexpectedEOF), &api.Options{ResponseStatus: 200})))
+
+	check.False(IsTransient(makeRequestError(errors.New("unauthorized"), &api.Options{ResponseStatus: 401})))
+	check.False(IsTransient(makeRequestError(errors.New("no such cursor"), &api.Options{ResponseStatus: 404})))
+	check.False(IsTransient(uqlProblem{title: "Bad request", status: 400}))
+	check.True(IsTransient(uqlProblem{title: "Unavailable", status: 503}))
+	check.False(IsTransient(errors.New("link with rel 'follow' not found in dataset")))
+}
+
 func TestAsStringOrNothing(t *testing.T) {
 	// given
 	notString := 12
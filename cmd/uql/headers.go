@@ -0,0 +1,92 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uql
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/cisco-open/fsoc/config"
+)
+
+// requestHeaders are added to every request of the default backend, see SetRequestHeaders
+var requestHeaders map[string]string
+
+// reservedHeaders are set by the platform API for authentication and content negotiation, they can't be overridden
+var reservedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Content-Type", "Content-Length", "Host", config.AppdPid, config.AppdTid, config.AppdPty}
+
+// headerNamePattern matches the token characters allowed in header names by RFC 7230
+var headerNamePattern = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// SetRequestHeaders parses the global --header values of the form name=value and the --tenant override, which
+// selects the tenant layer of the requests, and adds the resulting headers to every UQL request
+func SetRequestHeaders(headers []string, tenant string) error {
+	parsed, err := parseRequestHeaders(headers, tenant)
+	if err != nil {
+		return err
+	}
+	requestHeaders = parsed
+	return nil
+}
+
+func parseRequestHeaders(headers []string, tenant string) (map[string]string, error) {
+	results := make(map[string]string, len(headers)+2)
+	for _, header := range headers {
+		name, value, ok := strings.Cut(header, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !headerNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid header %q, expected name=value with a header name made of letters, digits and -", header)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("invalid header %q, values cannot contain line breaks", name)
+		}
+		name = http.CanonicalHeaderKey(name)
+		for _, reserved := range reservedHeaders {
+			if name == http.CanonicalHeaderKey(reserved) {
+				return nil, fmt.Errorf("header %q is set by fsoc and cannot be overridden", name)
+			}
+		}
+		results[name] = strings.TrimSpace(value)
+	}
+	if tenant != "" {
+		for _, name := range []string{"Layer-Type", "Layer-Id"} {
+			if _, ok := results[name]; ok {
+				return nil, fmt.Errorf("--tenant cannot be combined with a %q header", name)
+			}
+		}
+		results["Layer-Type"] = "TENANT"
+		results["Layer-Id"] = tenant
+	}
+	return results, nil
+}
+
+// withRequestHeaders returns the headers with the requestHeaders added, leaving the headers given by the caller
+// unchanged and taking precedence
+func withRequestHeaders(headers map[string]string) map[string]string {
+	if len(requestHeaders) == 0 {
+		return headers
+	}
+	results := make(map[string]string, len(headers)+len(requestHeaders))
+	for name, value := range requestHeaders {
+		results[name] = value
+	}
+	for name, value := range headers {
+		delete(results, http.CanonicalHeaderKey(name))
+		results[name] = value
+	}
+	return results
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRequestHeaders(t *testing.T) {
+	headers, err := parseRequestHeaders([]string{"x-request-source=dashboard", "X-Empty="}, "some-tenant")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"X-Request-Source": "dashboard",
+		"X-Empty":          "",
+		"Layer-Type":       "TENANT",
+		"Layer-Id":         "some-tenant",
+	}, headers)
+}
+
+func TestParseRequestHeaders_Invalid(t *testing.T) {
+	_, err := parseRequestHeaders([]string{"no-value"}, "")
+	assert.ErrorContains(t, err, "expected name=value")
+
+	_, err = parseRequestHeaders([]string{"bad name=value"}, "")
+	assert.ErrorContains(t, err, "expected name=value")
+
+	_, err = parseRequestHeaders([]string{"authorization=Bearer x"}, "")
+	assert.ErrorContains(t, err, "cannot be overridden")
+
+	_, err = parseRequestHeaders([]string{"appd-pid=x"}, "")
+	assert.ErrorContains(t, err, "cannot be overridden")
+
+	_, err = parseRequestHeaders([]string{"layer-id=x"}, "some-tenant")
+	assert.ErrorContains(t, err, "--tenant cannot be combined")
+}
+
+func TestWithRequestHeaders(t *testing.T) {
+	original := requestHeaders
+	defer func() { requestHeaders = original }()
+
+	requestHeaders = nil
+	assert.Nil(t, withRequestHeaders(nil))
+
+	requestHeaders = map[string]string{"Layer-Id": "override", "X-Extra": "1"}
+	assert.Equal(t, map[string]string{"layer-id": "caller", "X-Extra": "1"}, withRequestHeaders(map[string]string{"layer-id": "caller"}))
+}
@@ -0,0 +1,214 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/apex/log"
+)
+
+// PageFunc processes the data set of a page, numbered from 1, returning false to stop the iteration
+type PageFunc func(page int, dataSet *DataSet) (bool, error)
+
+// Pages iterates over the pages of a query result by following a link of each page's data set, performing the
+// checks shared by all paginating commands
+type Pages struct {
+	// Link is the rel of the link followed to the next page, "next" if empty
+	Link string
+
+	// Nested is set when the rows are in the data set nested in each page's main data set (see NestedDataSet),
+	// as for fetches of events or logs, rather than in the main data set itself
+	Nested bool
+
+	// Continue retrieves the page following the data set through the link. If nil, ClientV1.ContinueQuery is used
+	Continue func(dataSet *DataSet, rel string, page int) (*Response, error)
+
+	// Description names the query in the messages logged for incomplete pages, e.g., "events query"
+	Description string
+
+	// OnErrors, if set, receives the errors reported within continuation responses, in addition to their logging.
+	// Returning an error ends the iteration with that error, e.g., when incomplete data is not acceptable
+	OnErrors func(page int, errs []*Error) error
+}
+
+// IterateDataSet invokes fn with the main data set of the response and of each further page reached through the
+// link named linkName, see Pages.Iterate
+func IterateDataSet(resp *Response, linkName string, fn PageFunc) (*DataSet, error) {
+	return Pages{Link: linkName}.Iterate(resp, fn)
+}
+
+// Iterate invokes fn with the data set of the response's first page and of each further page, until fn returns
+// false or no page follows. It returns the data set of the last page processed, whose links can be used to
+// continue later, e.g., to follow. fn is not invoked if the response has no main data set.
+// Errors reported within continuation responses are logged, as the returned data may be incomplete, and a
+// continuation without a main data set ends the iteration
+func (p Pages) Iterate(resp *Response, fn PageFunc) (*DataSet, error) {
+	main := resp.Main()
+	if main == nil {
+		return nil, nil
+	}
+	dataSet, err := p.rows(main)
+	if err != nil {
+		return nil, err
+	}
+	more, err := fn(1, dataSet)
+	if err != nil || !more {
+		return dataSet, err
+	}
+	return p.IterateFrom(dataSet, 1, fn)
+}
+
+// IterateFrom is like Iterate, but starts with the page following the data set, numbered page. The data set
+// itself is not processed by fn, it may be one whose rows were already processed or one restored from a saved cursor
+func (p Pages) IterateFrom(dataSet *DataSet, page int, fn PageFunc) (*DataSet, error) {
+	link := p.Link
+	if link == "" {
+		link = "next"
+	}
+	continueQuery := p.Continue
+	if continueQuery == nil {
+		continueQuery = func(dataSet *DataSet, rel string, page int) (*Response, error) {
+			return ClientV1.ContinueQuery(dataSet, rel)
+		}
+	}
+
+	for page++; ; page++ {
+		if dataSet == nil {
+			return nil, nil
+		}
+		if _, ok := dataSet.Links[link]; !ok {
+			return dataSet, nil
+		}
+		resp, err := continueQuery(dataSet, link, page)
+		if err != nil {
+			return dataSet, fmt.Errorf("page %v uql.ClientV1.ContinueQuery: %w", page, err)
+		}
+		if resp.HasErrors() {
+			log.Errorf("Continuation of %v (page %v) encountered errors. Returned data may not be complete!", p.description(), page)
+			for _, e := range resp.Errors() {
+				log.Errorf("%s: %s", e.Title, e.Detail)
+			}
+			if p.OnErrors != nil {
+				if err := p.OnErrors(page, resp.Errors()); err != nil {
+					return dataSet, fmt.Errorf("page %v %w", page, err)
+				}
+			}
+		}
+		main := resp.Main()
+		if main == nil {
+			log.Errorf("Continuation of %v (page %v) has nil main data. Returned data may not be complete!", p.description(), page)
+			return dataSet, nil
+		}
+		dataSet, err = p.rows(main)
+		if err != nil {
+			return nil, fmt.Errorf("page %v %w", page, err)
+		}
+		more, err := fn(page, dataSet)
+		if err != nil || !more {
+			return dataSet, err
+		}
+	}
+}
+
+func (p Pages) rows(main *DataSet) (*DataSet, error) {
+	if p.Nested {
+		return NestedDataSet(main)
+	}
+	return main, nil
+}
+
+func (p Pages) description() string {
+	if p.Description == "" {
+		return "query"
+	}
+	return p.Description
+}
+
+// ShapeError is returned when the data sets of a response are not shaped as expected, describing the structure
+// that was received so that unexpected responses can be diagnosed
+type ShapeError struct {
+	// Expected describes the shape the command expected, e.g., "a *uql.DataSet in the first column of the first row"
+	Expected string
+
+	// Actual describes how the response differs from the expected shape
+	Actual string
+
+	// Structure describes the main data set received, see DescribeDataSet
+	Structure string
+}
+
+func (e *ShapeError) Error() string {
+	return fmt.Sprintf("%v, expected %v (response structure: %v)", e.Actual, e.Expected, e.Structure)
+}
+
+// nestedDataSetShape is the shape expected by NestedDataSet
+const nestedDataSetShape = "a *uql.DataSet in the first column of the first row of the main dataset"
+
+// FirstNestedDataSet returns the data set in the first column of the first row of the response's main data set, see
+// NestedDataSet. A *ShapeError is returned if the response is shaped differently
+func FirstNestedDataSet(resp *Response) (*DataSet, error) {
+	if resp == nil {
+		return nil, &ShapeError{Expected: nestedDataSetShape, Actual: "no response was received", Structure: DescribeDataSet(nil)}
+	}
+	return NestedDataSet(resp.Main())
+}
+
+// NestedDataSet returns the data set in the first column of the first row of the main data set, which is where
+// UQL returns the rows of fetches such as events(...) or logs. A *ShapeError is returned if the main data set is
+// shaped differently
+func NestedDataSet(main *DataSet) (*DataSet, error) {
+	shapeError := func(format string, args ...any) error {
+		return &ShapeError{Expected: nestedDataSetShape, Actual: fmt.Sprintf(format, args...), Structure: DescribeDataSet(main)}
+	}
+	if main == nil {
+		return nil, shapeError("response has no main dataset")
+	}
+	if len(main.Data) < 1 {
+		return nil, shapeError("main dataset %v has no rows", main.Name)
+	}
+	if len(main.Data[0]) < 1 {
+		return nil, shapeError("main dataset %v first row has no columns", main.Name)
+	}
+	nested, ok := main.Data[0][0].(*DataSet)
+	if !ok {
+		return nil, shapeError("main dataset %v first row first column (type %T) could not be converted to *uql.DataSet", main.Name, main.Data[0][0])
+	}
+	return nested, nil
+}
+
+// DescribeDataSet summarizes the structure of a data set for error messages: its name, the fields of its model,
+// its number of rows and the types of the values of its first row
+func DescribeDataSet(dataSet *DataSet) string {
+	if dataSet == nil {
+		return "no main dataset"
+	}
+	fields := []string{}
+	if dataSet.DataModel != nil {
+		for _, field := range dataSet.DataModel.Fields {
+			fields = append(fields, field.Alias+":"+field.Type)
+		}
+	}
+	description := fmt.Sprintf("dataset %v with fields [%v] and %v rows", dataSet.Name, strings.Join(fields, ", "), len(dataSet.Data))
+	if len(dataSet.Data) > 0 {
+		types := make([]string, 0, len(dataSet.Data[0]))
+		for _, value := range dataSet.Data[0] {
+			types = append(types, fmt.Sprintf("%T", value))
+		}
+		description += fmt.Sprintf(", first row [%v]", strings.Join(types, ", "))
+	}
+	return description
+}
@@ -0,0 +1,212 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// nestedPage returns a response whose main data set holds a nested data set named after the page, linking to the
+// next page if more is set
+func nestedPage(name string, more bool) *Response {
+	nested := &DataSet{Name: name, Links: map[string]Link{}}
+	if more {
+		nested.Links["next"] = Link{Href: "/" + name}
+	}
+	return &Response{mainDataSet: &DataSet{Name: "d:main", Data: [][]any{{nested}}}}
+}
+
+// continuePages returns a Continue function serving the responses in order
+func continuePages(responses ...*Response) func(*DataSet, string, int) (*Response, error) {
+	return func(dataSet *DataSet, rel string, page int) (*Response, error) {
+		if len(responses) < 1 {
+			return nil, errors.New("no more pages")
+		}
+		resp := responses[0]
+		responses = responses[1:]
+		return resp, nil
+	}
+}
+
+func TestPages_IterateNested(t *testing.T) {
+	pages := Pages{Nested: true, Continue: continuePages(nestedPage("p2", true), nestedPage("p3", false))}
+
+	var visited []string
+	last, err := pages.Iterate(nestedPage("p1", true), func(page int, dataSet *DataSet) (bool, error) {
+		visited = append(visited, dataSet.Name)
+		return true, nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"p1", "p2", "p3"}, visited)
+	assert.Equal(t, "p3", last.Name)
+}
+
+func TestPages_IterateStopsWhenRequested(t *testing.T) {
+	pages := Pages{Nested: true, Continue: continuePages(nestedPage("p2", true))}
+
+	var numbers []int
+	last, err := pages.Iterate(nestedPage("p1", true), func(page int, dataSet *DataSet) (bool, error) {
+		numbers = append(numbers, page)
+		return page < 2, nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2}, numbers)
+	assert.Equal(t, "p2", last.Name)
+}
+
+func TestPages_IterateContinuationFailure(t *testing.T) {
+	pages := Pages{Nested: true, Continue: continuePages()}
+
+	last, err := pages.Iterate(nestedPage("p1", true), func(page int, dataSet *DataSet) (bool, error) {
+		return true, nil
+	})
+
+	assert.ErrorContains(t, err, "page 2")
+	assert.Equal(t, "p1", last.Name)
+}
+
+func TestPages_IterateReportsContinuationErrors(t *testing.T) {
+	withErrors := nestedPage("p2", false)
+	withErrors.errors = []*Error{{Title: "partial", Detail: "some data is missing"}}
+
+	var reported []int
+	pages := Pages{Nested: true, Continue: continuePages(withErrors), OnErrors: func(page int, errs []*Error) error {
+		reported = append(reported, page)
+		assert.Equal(t, "partial", errs[0].Title)
+		return nil
+	}}
+
+	last, err := pages.Iterate(nestedPage("p1", true), func(page int, dataSet *DataSet) (bool, error) {
+		return true, nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{2}, reported)
+	assert.Equal(t, "p2", last.Name)
+}
+
+func TestPages_IterateFailsOnReportedErrors(t *testing.T) {
+	withErrors := nestedPage("p2", true)
+	withErrors.errors = []*Error{{Title: "partial", Detail: "some data is missing"}}
+
+	pages := Pages{Nested: true, Continue: continuePages(withErrors), OnErrors: func(page int, errs []*Error) error {
+		return errors.New("incomplete data")
+	}}
+
+	calls := 0
+	last, err := pages.Iterate(nestedPage("p1", true), func(page int, dataSet *DataSet) (bool, error) {
+		calls++
+		return true, nil
+	})
+
+	assert.EqualError(t, err, "page 2 incomplete data")
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "p1", last.Name)
+}
+
+func TestPages_IterateNilMainEndsIteration(t *testing.T) {
+	pages := Pages{Nested: true, Continue: continuePages(&Response{})}
+
+	calls := 0
+	last, err := pages.Iterate(nestedPage("p1", true), func(page int, dataSet *DataSet) (bool, error) {
+		calls++
+		return true, nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "p1", last.Name)
+
+	last, err = pages.Iterate(&Response{}, func(page int, dataSet *DataSet) (bool, error) {
+		t.Fatal("unexpected call for a response without main data set")
+		return false, nil
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, last)
+}
+
+func TestPages_IterateFrom(t *testing.T) {
+	pages := Pages{Nested: true, Continue: continuePages(nestedPage("p4", false))}
+	cursor := &DataSet{Links: map[string]Link{"next": {Href: "/saved"}}}
+
+	var numbers []int
+	last, err := pages.IterateFrom(cursor, 3, func(page int, dataSet *DataSet) (bool, error) {
+		numbers = append(numbers, page)
+		return true, nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{4}, numbers)
+	assert.Equal(t, "p4", last.Name)
+}
+
+func TestIterateDataSet(t *testing.T) {
+	main := &DataSet{Name: "d:main", Data: [][]any{{"a"}, {"b"}}}
+
+	rows := 0
+	last, err := IterateDataSet(&Response{mainDataSet: main}, "next", func(page int, dataSet *DataSet) (bool, error) {
+		rows += len(dataSet.Data)
+		return true, nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, rows)
+	assert.Same(t, main, last)
+}
+
+func TestNestedDataSet(t *testing.T) {
+	_, err := NestedDataSet(&DataSet{Name: "d:main"})
+	assert.ErrorContains(t, err, "has no rows")
+
+	_, err = NestedDataSet(&DataSet{Name: "d:main", Data: [][]any{{}}})
+	assert.ErrorContains(t, err, "first row has no columns")
+
+	_, err = NestedDataSet(&DataSet{Name: "d:main", Data: [][]any{{"value"}}})
+	assert.ErrorContains(t, err, "could not be converted")
+
+	nested := &DataSet{Name: "d:nested"}
+	dataSet, err := NestedDataSet(&DataSet{Name: "d:main", Data: [][]any{{nested}}})
+	assert.Nil(t, err)
+	assert.Same(t, nested, dataSet)
+}
+
+func TestFirstNestedDataSet(t *testing.T) {
+	_, err := FirstNestedDataSet(&Response{})
+	var shapeErr *ShapeError
+	assert.ErrorAs(t, err, &shapeErr)
+	assert.Equal(t, "response has no main dataset", shapeErr.Actual)
+	assert.Equal(t, "no main dataset", shapeErr.Structure)
+
+	main := &DataSet{
+		Name:      "d:main",
+		DataModel: &Model{Fields: []ModelField{{Alias: "count", Type: "number"}}},
+		Data:      [][]any{{float64(3)}},
+	}
+	_, err = FirstNestedDataSet(&Response{mainDataSet: main})
+	assert.ErrorAs(t, err, &shapeErr)
+	assert.Contains(t, shapeErr.Actual, "(type float64) could not be converted")
+	assert.Equal(t, "dataset d:main with fields [count:number] and 1 rows, first row [float64]", shapeErr.Structure)
+	assert.ErrorContains(t, err, "expected a *uql.DataSet in the first column of the first row of the main dataset")
+
+	nested := &DataSet{Name: "d:nested"}
+	dataSet, err := FirstNestedDataSet(&Response{mainDataSet: &DataSet{Name: "d:main", Data: [][]any{{nested}}}})
+	assert.Nil(t, err)
+	assert.Same(t, nested, dataSet)
+}
@@ -0,0 +1,103 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uql
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// DefaultRequestsPerSecond is the default ceiling of the rate of UQL requests, shared by all concurrent queries
+const DefaultRequestsPerSecond = 10
+
+// maxJitterFraction bounds the random delay added to waits, as a fraction of the wait, so that requests held back
+// together are not released together
+const maxJitterFraction = 0.1
+
+// requestLimiter bounds the rate of the requests of the default backend
+var requestLimiter = newTokenBucket(DefaultRequestsPerSecond)
+
+// SetRequestRate sets the ceiling of the rate of UQL requests, in requests per second; 0 disables rate limiting.
+// It is set by the global --rps flag
+func SetRequestRate(requestsPerSecond float64) {
+	requestLimiter.setRate(requestsPerSecond)
+}
+
+// tokenBucket is a token bucket rate limiter. Its burst is the rate rounded up, so that up to a second's worth of
+// requests may be sent at once after a pause
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+	sleep  func(time.Duration)
+	jitter func(max time.Duration) time.Duration
+}
+
+func newTokenBucket(requestsPerSecond float64) *tokenBucket {
+	b := &tokenBucket{
+		now:   time.Now,
+		sleep: time.Sleep,
+		jitter: func(max time.Duration) time.Duration {
+			if max <= 0 {
+				return 0
+			}
+			return time.Duration(rand.Int63n(int64(max)))
+		},
+	}
+	b.setRate(requestsPerSecond)
+	return b
+}
+
+func (b *tokenBucket) setRate(requestsPerSecond float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rate = math.Max(requestsPerSecond, 0)
+	b.tokens = b.burst()
+	b.last = b.now()
+}
+
+func (b *tokenBucket) burst() float64 {
+	return math.Max(math.Ceil(b.rate), 1)
+}
+
+// wait blocks until a request may be sent
+func (b *tokenBucket) wait() {
+	if delay := b.reserve(); delay > 0 {
+		b.sleep(delay)
+	}
+}
+
+// reserve takes a token and returns how long to wait before using it. Tokens taken ahead of their availability
+// are accounted as debt, so that concurrent callers are spaced out rather than released together
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.rate == 0 {
+		return 0
+	}
+	now := b.now()
+	b.tokens = math.Min(b.burst(), b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	return delay + b.jitter(time.Duration(float64(delay)*maxJitterFraction))
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock returns a token bucket driven by a manual clock, without jitter
+func fakeClock(requestsPerSecond float64) (*tokenBucket, *time.Time) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := &tokenBucket{
+		now:    func() time.Time { return now },
+		sleep:  func(time.Duration) {},
+		jitter: func(time.Duration) time.Duration { return 0 },
+	}
+	b.setRate(requestsPerSecond)
+	return b, &now
+}
+
+func TestTokenBucket_Burst(t *testing.T) {
+	b, _ := fakeClock(2)
+
+	assert.Equal(t, time.Duration(0), b.reserve())
+	assert.Equal(t, time.Duration(0), b.reserve())
+	assert.Equal(t, 500*time.Millisecond, b.reserve())
+	assert.Equal(t, time.Second, b.reserve())
+}
+
+func TestTokenBucket_Refill(t *testing.T) {
+	b, now := fakeClock(2)
+	b.reserve()
+	b.reserve()
+
+	*now = now.Add(500 * time.Millisecond)
+	assert.Equal(t, time.Duration(0), b.reserve())
+
+	*now = now.Add(10 * time.Second)
+	assert.Equal(t, time.Duration(0), b.reserve())
+	assert.Equal(t, time.Duration(0), b.reserve())
+	assert.Equal(t, 500*time.Millisecond, b.reserve(), "refill is capped at the burst")
+}
+
+func TestTokenBucket_Unlimited(t *testing.T) {
+	b, _ := fakeClock(0)
+
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, time.Duration(0), b.reserve())
+	}
+}
+
+func TestTokenBucket_FractionalRate(t *testing.T) {
+	b, _ := fakeClock(0.5)
+
+	assert.Equal(t, time.Duration(0), b.reserve())
+	assert.Equal(t, 2*time.Second, b.reserve())
+}
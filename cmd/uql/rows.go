@@ -0,0 +1,59 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AllRows returns the rows of the response's main data set, nil if the response has no main data set
+func (resp *Response) AllRows() [][]any {
+	if resp == nil {
+		return nil
+	}
+	return resp.Main().AllRows()
+}
+
+// AllRows returns the rows of the data set, nil for a nil data set
+func (d *DataSet) AllRows() [][]any {
+	if d == nil {
+		return nil
+	}
+	return d.Data
+}
+
+// ColumnByName returns the value of the row in the column whose model field has the given alias, e.g.,
+// "timestamp" for the fields of fetched events. An error describing the columns of the data set is returned if
+// the data set has no such column or the row is too short to hold it
+func (d *DataSet) ColumnByName(row []any, name string) (any, error) {
+	if d == nil || d.DataModel == nil {
+		return nil, fmt.Errorf("dataset has no model to find column %q in", name)
+	}
+	for index, field := range d.DataModel.Fields {
+		if field.Alias != name {
+			continue
+		}
+		if index >= len(row) {
+			return nil, fmt.Errorf("dataset %v row has %v columns, too few for column %q at index %v", d.Name, len(row), name, index)
+		}
+		return row[index], nil
+	}
+	aliases := make([]string, 0, len(d.DataModel.Fields))
+	for _, field := range d.DataModel.Fields {
+		aliases = append(aliases, field.Alias)
+	}
+	return nil, fmt.Errorf("dataset %v has no column %q, its columns are [%v]", d.Name, name, strings.Join(aliases, ", "))
+}
@@ -0,0 +1,50 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllRows(t *testing.T) {
+	var resp *Response
+	assert.Nil(t, resp.AllRows())
+	assert.Nil(t, (&Response{}).AllRows())
+
+	main := &DataSet{Name: "d:main", Data: [][]any{{"a"}, {"b"}}}
+	assert.Equal(t, [][]any{{"a"}, {"b"}}, (&Response{mainDataSet: main}).AllRows())
+}
+
+func TestColumnByName(t *testing.T) {
+	dataSet := &DataSet{
+		Name:      "d:events",
+		DataModel: &Model{Fields: []ModelField{{Alias: "attributes", Type: "complex"}, {Alias: "timestamp", Type: "timestamp"}}},
+	}
+
+	value, err := dataSet.ColumnByName([]any{"attrs", "ts"}, "timestamp")
+	assert.Nil(t, err)
+	assert.Equal(t, "ts", value)
+
+	_, err = dataSet.ColumnByName([]any{"attrs"}, "timestamp")
+	assert.ErrorContains(t, err, "row has 1 columns, too few for column \"timestamp\" at index 1")
+
+	_, err = dataSet.ColumnByName([]any{"attrs", "ts"}, "raw")
+	assert.ErrorContains(t, err, "has no column \"raw\", its columns are [attributes, timestamp]")
+
+	_, err = (&DataSet{Name: "d:events"}).ColumnByName([]any{"attrs"}, "attributes")
+	assert.ErrorContains(t, err, "no model")
+}
@@ -0,0 +1,143 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uql
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTimingDescriptionLength bounds the length of query descriptions in timing summaries
+const maxTimingDescriptionLength = 80
+
+// QueryTiming holds the accumulated wall time and number of pages retrieved for a query and all its continuations
+type QueryTiming struct {
+	Description string
+	Pages       int
+	Duration    time.Duration
+}
+
+func (t QueryTiming) String() string {
+	pages := "pages"
+	if t.Pages == 1 {
+		pages = "page"
+	}
+	return fmt.Sprintf("%v: %v %v, %v", t.Description, t.Pages, pages, t.Duration.Round(time.Millisecond))
+}
+
+// TimingClient wraps a UqlClient, recording the wall time and page count of each executed query.
+// Continuations are attributed to the query whose response contained the continued data set. Only the data sets
+// which can still be continued are indexed, so that the index stays bounded while following a cursor.
+// It is meant to be swapped in only when timing is requested, so that regular clients bear no overhead
+type TimingClient struct {
+	client    UqlClient
+	mu        sync.Mutex
+	timings   []*QueryTiming
+	byDataSet map[*DataSet]*QueryTiming
+}
+
+func NewTimingClient(client UqlClient) *TimingClient {
+	return &TimingClient{
+		client:    client,
+		byDataSet: make(map[*DataSet]*QueryTiming),
+	}
+}
+
+func (c *TimingClient) ExecuteQuery(query *Query) (*Response, error) {
+	description := ""
+	if query != nil {
+		description = describeQuery(query.Str)
+	}
+	timing := &QueryTiming{Description: description}
+	c.mu.Lock()
+	c.timings = append(c.timings, timing)
+	c.mu.Unlock()
+
+	start := time.Now()
+	resp, err := c.client.ExecuteQuery(query)
+	c.record(timing, nil, resp, time.Since(start))
+	return resp, err
+}
+
+func (c *TimingClient) ContinueQuery(dataSet *DataSet, rel string) (*Response, error) {
+	c.mu.Lock()
+	timing, ok := c.byDataSet[dataSet]
+	if !ok {
+		timing = &QueryTiming{Description: fmt.Sprintf("continuation (%v)", rel)}
+		c.timings = append(c.timings, timing)
+	}
+	c.mu.Unlock()
+
+	start := time.Now()
+	resp, err := c.client.ContinueQuery(dataSet, rel)
+	c.record(timing, dataSet, resp, time.Since(start))
+	return resp, err
+}
+
+// Timings returns a snapshot of the timings recorded so far, in the order the queries were first executed
+func (c *TimingClient) Timings() []QueryTiming {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	results := make([]QueryTiming, 0, len(c.timings))
+	for _, timing := range c.timings {
+		results = append(results, *timing)
+	}
+	return results
+}
+
+// record adds the page to the timing entry. The data sets of the response replace the continued data set, if any,
+// which is kept should the continuation return no data, e.g., on failure, as it may then be continued again
+func (c *TimingClient) record(timing *QueryTiming, continued *DataSet, resp *Response, elapsed time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timing.Pages++
+	timing.Duration += elapsed
+	if resp == nil || resp.Main() == nil {
+		return
+	}
+	if continued != nil {
+		delete(c.byDataSet, continued)
+	}
+	registerDataSets(resp.Main(), timing, c.byDataSet)
+}
+
+// registerDataSets associates the data set and any data sets nested in it that have links to continue them with the
+// timing entry
+func registerDataSets(dataSet *DataSet, timing *QueryTiming, index map[*DataSet]*QueryTiming) {
+	if dataSet == nil {
+		return
+	}
+	if len(dataSet.Links) > 0 {
+		index[dataSet] = timing
+	}
+	for _, row := range dataSet.Data {
+		for _, value := range row {
+			if nested, ok := value.(*DataSet); ok {
+				registerDataSets(nested, timing, index)
+			}
+		}
+	}
+}
+
+// describeQuery collapses the whitespace of a query and truncates it for display
+func describeQuery(query string) string {
+	description := strings.Join(strings.Fields(query), " ")
+	if runes := []rune(description); len(runes) > maxTimingDescriptionLength {
+		description = string(runes[:maxTimingDescriptionLength-3]) + "..."
+	}
+	return description
+}
@@ -0,0 +1,81 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package uql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pagingClient struct{}
+
+func (pagingClient) ExecuteQuery(query *Query) (*Response, error) {
+	return pagedResponse(), nil
+}
+
+func (pagingClient) ContinueQuery(dataSet *DataSet, rel string) (*Response, error) {
+	return pagedResponse(), nil
+}
+
+func pagedResponse() *Response {
+	nested := &DataSet{Name: "d:events-1", Links: map[string]Link{"next": {Href: "/next"}}}
+	return &Response{mainDataSet: &DataSet{Name: "d:main", Data: [][]any{{nested}}}}
+}
+
+func TestTimingClient_AttributesContinuationsToQuery(t *testing.T) {
+	// given
+	client := NewTimingClient(pagingClient{})
+
+	// when
+	resp, _ := client.ExecuteQuery(&Query{Str: "FETCH events(optimize:optimization_started)\n\t{attributes}"})
+	resp, _ = client.ContinueQuery(resp.Main().Data[0][0].(*DataSet), "next")
+	_, _ = client.ContinueQuery(resp.Main().Data[0][0].(*DataSet), "next")
+	_, _ = client.ExecuteQuery(&Query{Str: "FETCH id FROM entities"})
+
+	// then
+	timings := client.Timings()
+	assert.Len(t, timings, 2)
+	assert.Equal(t, "FETCH events(optimize:optimization_started) {attributes}", timings[0].Description)
+	assert.Equal(t, 3, timings[0].Pages)
+	assert.Equal(t, "FETCH id FROM entities", timings[1].Description)
+	assert.Equal(t, 1, timings[1].Pages)
+}
+
+func TestTimingClient_UnknownContinuation(t *testing.T) {
+	client := NewTimingClient(pagingClient{})
+
+	_, _ = client.ContinueQuery(&DataSet{}, "follow")
+
+	timings := client.Timings()
+	assert.Len(t, timings, 1)
+	assert.Equal(t, "continuation (follow)", timings[0].Description)
+	assert.Equal(t, 1, timings[0].Pages)
+}
+
+func TestTimingClient_ForgetsContinuedDataSets(t *testing.T) {
+	client := NewTimingClient(pagingClient{})
+
+	resp, _ := client.ExecuteQuery(&Query{Str: "FETCH events(optimize:optimization_started)"})
+	for i := 0; i < 10; i++ {
+		resp, _ = client.ContinueQuery(resp.Main().Data[0][0].(*DataSet), "follow")
+	}
+
+	// only the last cursor, which has links, remains indexed
+	assert.Len(t, client.byDataSet, 1)
+	timings := client.Timings()
+	assert.Len(t, timings, 1)
+	assert.Equal(t, 11, timings[0].Pages)
+}
@@ -187,6 +187,9 @@ func changeFlagUsage(cmd *cobra.Command) {
 
 func printProblemDescription(cmd *cobra.Command, problem uqlProblem, inputQuery string) {
 	cmd.Printf("%s\n%s\n\n", problem.title, problem.detail)
+	if prefix := describeRequest(problem.status, problem.requestId); prefix != "" {
+		cmd.Printf("%s\n\n", strings.TrimSuffix(prefix, ": "))
+	}
 	if len(problem.errorDetails) != 0 {
 		var query string
 		// Sometimes, the query is not reported back in the problem json
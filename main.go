@@ -16,12 +16,14 @@ package main
 
 import (
 	"context"
+	"errors"
 	"os"
 
 	"github.com/apex/log"
 	"github.com/apex/log/handlers/cli"
 
 	"github.com/cisco-open/fsoc/cmd"
+	"github.com/cisco-open/fsoc/cmdkit"
 )
 
 func main() {
@@ -35,6 +37,10 @@ func realMain() int {
 
 	if err := cmd.Execute(ctx); err != nil {
 		log.WithFields(log.Fields{"error": err}).Error("command failed")
+		var exitErr cmdkit.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.Code
+		}
 		return 1
 	}
 	return 0
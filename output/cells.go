@@ -0,0 +1,140 @@
+// Copyright 2022 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ellipsis marks the truncated cells, counting as one character of the width
+const ellipsis = "…"
+
+// ansiEscape matches the ANSI escape sequences, such as color codes, which take no width on a terminal
+var ansiEscape = regexp.MustCompile("^\x1b\\[[0-9;?]*[ -/]*[@-~]")
+
+// fitCells limits the cells of the table lines to width characters, counted by visible rune, either truncating them
+// with an ellipsis or wrapping them onto multiple lines. ANSI escape sequences, e.g., of colored cells, are kept and
+// take no width. The lines are replaced, the original cells are left intact
+func fitCells(t *Table, width int, wrap bool) {
+	if t == nil || width < 1 {
+		return
+	}
+	lines := make([][]string, 0, len(t.Lines))
+	for _, line := range t.Lines {
+		fitted := make([]string, 0, len(line))
+		for _, cell := range line {
+			if wrap {
+				fitted = append(fitted, wrapCell(cell, width))
+			} else {
+				fitted = append(fitted, truncateCell(cell, width))
+			}
+		}
+		lines = append(lines, fitted)
+	}
+	t.Lines = lines
+}
+
+// truncateCell truncates each line of the cell beyond width visible runes, ending it with an ellipsis. The escape
+// sequences of the truncated runes are kept, so that, e.g., the color of a truncated cell is still reset
+func truncateCell(cell string, width int) string {
+	cellLines := strings.Split(cell, "\n")
+	for i, line := range cellLines {
+		runes, trailing := splitEscapes(line)
+		if len(runes) > width {
+			cellLines[i] = joinRunes(runes[:width-1]) + ellipsis + joinEscapes(runes[width-1:]) + trailing
+		}
+	}
+	return strings.Join(cellLines, "\n")
+}
+
+// wrapCell breaks each line of the cell into lines of at most width visible runes, at the last space when there is
+// one. The escape sequences of the spaces dropped at the breaks are kept
+func wrapCell(cell string, width int) string {
+	var wrapped []string
+	for _, line := range strings.Split(cell, "\n") {
+		runes, trailing := splitEscapes(line)
+		for len(runes) > width {
+			split := width
+			for i := width; i > 0; i-- {
+				if runes[i].r == ' ' {
+					split = i
+					break
+				}
+			}
+			end := split
+			for end > 0 && runes[end-1].r == ' ' {
+				end--
+			}
+			wrapped = append(wrapped, joinRunes(runes[:end])+joinEscapes(runes[end:split]))
+
+			start := split
+			for start < len(runes) && runes[start].r == ' ' {
+				start++
+			}
+			skipped := joinEscapes(runes[split:start])
+			runes = runes[start:]
+			if len(runes) > 0 {
+				runes[0].escapes = skipped + runes[0].escapes
+			} else {
+				trailing = skipped + trailing
+			}
+		}
+		wrapped = append(wrapped, joinRunes(runes)+trailing)
+	}
+	return strings.Join(wrapped, "\n")
+}
+
+// styledRune is a visible rune of a cell line along with the escape sequences preceding it
+type styledRune struct {
+	escapes string
+	r       rune
+}
+
+// splitEscapes splits the line into its visible runes, along with the escape sequences following the last one
+func splitEscapes(line string) ([]styledRune, string) {
+	runes := make([]styledRune, 0, len(line))
+	escapes := ""
+	for len(line) > 0 {
+		if escape := ansiEscape.FindString(line); escape != "" {
+			escapes += escape
+			line = line[len(escape):]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(line)
+		runes = append(runes, styledRune{escapes: escapes, r: r})
+		escapes = ""
+		line = line[size:]
+	}
+	return runes, escapes
+}
+
+func joinRunes(runes []styledRune) string {
+	var sb strings.Builder
+	for _, r := range runes {
+		sb.WriteString(r.escapes)
+		sb.WriteRune(r.r)
+	}
+	return sb.String()
+}
+
+func joinEscapes(runes []styledRune) string {
+	var sb strings.Builder
+	for _, r := range runes {
+		sb.WriteString(r.escapes)
+	}
+	return sb.String()
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/itchyny/gojq"
+)
+
+// ValidateFields checks that each column of a fields specification (as used by --fields and the fields
+// annotations) is a valid JQ object construction entry, e.g., `Type: .EventAttributes["appd.event.type"], TS: .Timestamp`.
+// The returned error names the first offending column
+func ValidateFields(fieldsCommaList string) error {
+	if strings.TrimSpace(fieldsCommaList) == "*" {
+		return nil
+	}
+	for _, column := range splitFieldColumns(fieldsCommaList) {
+		name, _, _ := strings.Cut(column, ":")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return fmt.Errorf("field column %q has no name", strings.TrimSpace(column))
+		}
+		if _, err := gojq.Parse(fmt.Sprintf("{%s}", column)); err != nil {
+			return fmt.Errorf("invalid expression for field column %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// splitFieldColumns splits a fields specification on the commas separating columns, ignoring
+// commas nested in brackets, parentheses, braces or string literals
+func splitFieldColumns(fieldsCommaList string) []string {
+	columns := []string{}
+	depth := 0
+	inString := false
+	escaped := false
+	start := 0
+	for i, r := range fieldsCommaList {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && r == '\\':
+			escaped = true
+		case r == '"':
+			inString = !inString
+		case inString:
+		case r == '(' || r == '[' || r == '{':
+			depth++
+		case r == ')' || r == ']' || r == '}':
+			depth--
+		case r == ',' && depth == 0:
+			columns = append(columns, fieldsCommaList[start:i])
+			start = i + 1
+		}
+	}
+	return append(columns, fieldsCommaList[start:])
+}
@@ -0,0 +1,192 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/cisco-open/fsoc/platform/api"
+)
+
+// htmlNow returns the generation time shown in HTML reports
+var htmlNow = time.Now
+
+// htmlReportTemplate renders a self-contained page, so that the report can be shared as a single file
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; font-size: 14px; margin: 2em; color: #222; }
+header { margin-bottom: 1.5em; }
+h1 { font-size: 1.4em; margin: 0 0 0.3em 0; }
+dl { display: grid; grid-template-columns: max-content auto; gap: 0.2em 1em; margin: 0.5em 0; }
+dt { font-weight: bold; }
+dd { margin: 0; font-family: monospace; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f0f0f0; }
+tr:nth-child(even) td { background: #fafafa; }
+pre { margin: 0.3em 0 0 0; }
+summary { cursor: pointer; color: #555; }
+.total { color: #555; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+<div>Generated at {{.Generated}}</div>
+{{- if .Parameters}}
+<dl>
+{{- range .Parameters}}
+<dt>{{.Name}}</dt><dd>{{.Value}}</dd>
+{{- end}}
+</dl>
+{{- end}}
+</header>
+<table>
+<thead>
+<tr>{{range .Headers}}<th>{{.}}</th>{{end}}</tr>
+</thead>
+<tbody>
+{{- range .Rows}}
+<tr>{{range .}}<td>{{if .Nested}}<details><summary>{{.Summary}}</summary><pre>{{.Text}}</pre></details>{{else}}{{.Text}}{{end}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+<p class="total">Total: {{len .Rows}}</p>
+</body>
+</html>
+`))
+
+type htmlReport struct {
+	Title      string
+	Generated  string
+	Parameters []htmlParameter
+	Headers    []string
+	Rows       [][]htmlCell
+}
+
+type htmlParameter struct {
+	Name  string
+	Value string
+}
+
+// htmlCell is a table cell, Nested if it holds a JSON object or array, which is shown indented and collapsed
+type htmlCell struct {
+	Text    string
+	Nested  bool
+	Summary string
+}
+
+// printHtml renders the table as an HTML page, headed by the command and the flags set on its command line
+func printHtml(cmd *cobra.Command, t *Table) error {
+	report := htmlReport{
+		Title:     "fsoc report",
+		Generated: htmlNow().Format(time.RFC3339),
+		Headers:   t.Headers,
+		Rows:      make([][]htmlCell, 0, len(t.Lines)),
+	}
+	if cmd != nil {
+		report.Title = cmd.CommandPath()
+		report.Parameters = htmlParameters(cmd)
+	}
+	for _, line := range t.Lines {
+		cells := make([]htmlCell, 0, len(line))
+		for _, value := range line {
+			cells = append(cells, newHtmlCell(value))
+		}
+		report.Rows = append(report.Rows, cells)
+	}
+
+	var page bytes.Buffer
+	if err := htmlReportTemplate.Execute(&page, report); err != nil {
+		return err
+	}
+	print(cmd, page.String())
+	return nil
+}
+
+// htmlParameters lists the flags set on the command line, which hold the query parameters, except for those only
+// selecting where and how the output is written. The values of the --header flags that may carry credentials are
+// redacted, so that the report can be shared
+func htmlParameters(cmd *cobra.Command) []htmlParameter {
+	parameters := []htmlParameter{}
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		switch flag.Name {
+		case "output", "output-file":
+			return
+		}
+		value := flag.Value.String()
+		if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+			values := sliceValue.GetSlice()
+			if flag.Name == "header" {
+				values = redactHeaders(values)
+			}
+			value = strings.Join(values, ", ")
+		}
+		parameters = append(parameters, htmlParameter{Name: "--" + flag.Name, Value: value})
+	})
+	sort.Slice(parameters, func(i, j int) bool {
+		return parameters[i].Name < parameters[j].Name
+	})
+	return parameters
+}
+
+// redactHeaders returns the name=value headers with the values of those that may carry credentials redacted
+func redactHeaders(headers []string) []string {
+	redacted := make([]string, 0, len(headers))
+	for _, header := range headers {
+		if name, _, found := strings.Cut(header, "="); found && api.IsRedactedHeader(strings.TrimSpace(name)) {
+			header = name + "=REDACTED"
+		}
+		redacted = append(redacted, header)
+	}
+	return redacted
+}
+
+// newHtmlCell detects the cells holding a JSON object or array, as the fields query renders nested attributes
+func newHtmlCell(value string) htmlCell {
+	trimmed := strings.TrimSpace(value)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return htmlCell{Text: value}
+	}
+	var nested any
+	if err := json.Unmarshal([]byte(trimmed), &nested); err != nil {
+		return htmlCell{Text: value}
+	}
+	indented, err := json.MarshalIndent(nested, "", JsonIndent)
+	if err != nil {
+		return htmlCell{Text: value}
+	}
+	summary := "JSON"
+	switch nested := nested.(type) {
+	case map[string]any:
+		summary = fmt.Sprintf("%v attributes", len(nested))
+	case []any:
+		summary = fmt.Sprintf("%v values", len(nested))
+	}
+	return htmlCell{Text: string(indented), Nested: true, Summary: summary}
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/itchyny/gojq"
+)
+
+// CompileJq parses and compiles a --jq expression, so that it can be rejected before a command does any work
+func CompileJq(expression string) (*gojq.Code, error) {
+	query, err := gojq.Parse(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jq expression %q: %w", expression, err)
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid jq expression %q: %w", expression, err)
+	}
+	return code, nil
+}
+
+// runJq runs the output data through the jq expression and returns all the values it produces, in order. The
+// data is first converted to the generic form a JSON parse would produce, which is what gojq operates on
+func runJq(v any, expression string) ([]any, error) {
+	code, err := CompileJq(expression)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert output data to JSON: %w", err)
+	}
+	var input any
+	if err := json.Unmarshal(data, &input); err != nil {
+		return nil, fmt.Errorf("failed to convert output data back from JSON: %w", err)
+	}
+
+	results := make([]any, 0, 1)
+	iter := code.Run(input)
+	for {
+		result, ok := iter.Next()
+		if !ok {
+			return results, nil
+		}
+		if err, ok := result.(error); ok {
+			return nil, fmt.Errorf("jq expression %q failed: %w", expression, err)
+		}
+		results = append(results, result)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// markdownEscaper escapes the cell values that would break a GitHub-flavored Markdown table: pipes would end the
+// cell and line breaks the row
+var markdownEscaper = strings.NewReplacer("\\", "\\\\", "|", "\\|", "\r\n", "<br>", "\n", "<br>")
+
+// printMarkdown prints the table as a GitHub-flavored Markdown table, or, for the detail layout, as a list of
+// "**label**: value" items per entry, separating the entries with a blank line
+func printMarkdown(cmd *cobra.Command, t *Table, detail bool) {
+	if t == nil {
+		printSimple(cmd, "Nothing to display")
+		return
+	}
+	var sb strings.Builder
+	if detail {
+		for i, line := range t.Lines {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			for j, header := range t.Headers {
+				value := ""
+				if j < len(line) {
+					value = line[j]
+				}
+				sb.WriteString("- **" + markdownEscaper.Replace(header) + "**: " + markdownEscaper.Replace(value) + "\n")
+			}
+		}
+	} else {
+		if !t.OmitHeaders {
+			writeMarkdownRow(&sb, t.Headers)
+			separators := make([]string, len(t.Headers))
+			for i := range separators {
+				separators[i] = "---"
+			}
+			writeMarkdownRow(&sb, separators)
+		}
+		for _, line := range t.Lines {
+			writeMarkdownRow(&sb, line)
+		}
+	}
+	print(cmd, sb.String())
+}
+
+func writeMarkdownRow(sb *strings.Builder, cells []string) {
+	sb.WriteString("|")
+	for _, cell := range cells {
+		sb.WriteString(" " + markdownEscaper.Replace(cell) + " |")
+	}
+	sb.WriteString("\n")
+}
@@ -45,6 +45,10 @@ type printRequest struct {
 	cmd         *cobra.Command
 	format      string
 	fields      string
+	transpose   bool   // print tables with one "label: value" line per field, as the detail format does
+	maxColWidth int    // limit the table cells to the given number of characters, 0 for no limit
+	wrap        bool   // wrap the cells beyond maxColWidth rather than truncating them
+	jq          string // jq expression transforming the data in the JSON formats, see runJq
 	annotations map[string]string
 }
 
@@ -80,6 +84,17 @@ func GetOutWriter(cmd *cobra.Command) io.Writer {
 	}
 }
 
+// FlushCmdOutput flushes the command's output writer if it buffers or syncs its writes, e.g., the file of
+// --output-file. Errors are ignored, as terminals and pipes can't be synced
+func FlushCmdOutput(cmd *cobra.Command) {
+	switch w := GetOutWriter(cmd).(type) {
+	case interface{ Flush() error }:
+		_ = w.Flush()
+	case interface{ Sync() error }:
+		_ = w.Sync()
+	}
+}
+
 func WriteJson(obj interface{}, w io.Writer) error {
 	data, err := json.MarshalIndent(obj, "", JsonIndent)
 	if err != nil {
@@ -90,6 +105,17 @@ func WriteJson(obj interface{}, w io.Writer) error {
 	return err
 }
 
+// WriteJsonCompact writes the object as single-line, unindented JSON
+func WriteJsonCompact(obj interface{}, w io.Writer) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = w.Write(data)
+	return err
+}
+
 // PrintJson displays the output in prettified JSON
 func PrintJson(cmd *cobra.Command, v any) error {
 	return WriteJson(v, GetOutWriter(cmd))
@@ -147,23 +173,33 @@ func PrintCmdOutputCustom(cmd *cobra.Command, v any, table *Table) {
 	}
 
 	// select which fields filter specification to use
-	// Logic: if the --fields flag is specified, always use it (for all formats)
+	// Logic: if the --fields flag is specified, use it for all but the JSON formats
 	//        otherwise
 	//        - for human outputs only, get the fields spec from the command annotations (if set)
 	//        - for machine formats, don't filter by fields
 	fields, _ := cmd.Flags().GetString("fields") // since --fields doesn't have default, non-empty means explicitly set
-	pr := printRequest{cmd: cmd, format: format, fields: fields, annotations: cmd.Annotations}
+	transpose, _ := cmd.Flags().GetBool("transpose")
+	maxColWidth, _ := cmd.Flags().GetInt("max-col-width")
+	wrap, _ := cmd.Flags().GetBool("wrap")
+	jq, _ := cmd.Flags().GetString("jq")
+	pr := printRequest{cmd: cmd, format: format, fields: fields, transpose: transpose, maxColWidth: maxColWidth, wrap: wrap, jq: jq, annotations: cmd.Annotations}
 	printCmdOutputCustom(pr, v, table)
 }
 
 func printCmdOutputCustom(pr printRequest, v any, table *Table) {
+	// JSON output always carries the full data; use --jq to reshape it instead
+	if pr.fields != "" && (pr.format == "json" || pr.format == "json-compact") {
+		log.Debugf("Ignoring --fields %q for %s output", pr.fields, pr.format)
+		pr.fields = ""
+	}
+
 	// if no field spec is given on the command line and built-in specs are available, use them
 	// as long as there is no custom table
 	if pr.fields == "" && pr.annotations != nil && (table == nil || table.Headers == nil) {
 		// choose which annotations to use and in what priority order
 		annotations := []string{} // names of annotations to use for fields, in priority order
 		switch pr.format {
-		case "", "auto", "table":
+		case "", "auto", "table", "html", "markdown":
 			annotations = []string{TableFieldsAnnotation, DetailFieldsAnnotation}
 		case "detail":
 			annotations = []string{DetailFieldsAnnotation, TableFieldsAnnotation}
@@ -195,9 +231,24 @@ func printCmdOutputCustom(pr printRequest, v any, table *Table) {
 
 	// print according to format and presence of table
 	switch pr.format {
-	case "json":
-		if err := PrintJson(pr.cmd, v); err != nil {
-			log.Fatalf("Failed to convert output to JSON: %v (%+v)", err, v)
+	case "json", "json-compact":
+		values := []any{v}
+		if pr.jq != "" {
+			var err error
+			if values, err = runJq(v, pr.jq); err != nil {
+				log.Fatalf("Failed to apply --jq: %v", err)
+			}
+		}
+		for _, value := range values {
+			var err error
+			if pr.format == "json" {
+				err = PrintJson(pr.cmd, value)
+			} else {
+				err = WriteJsonCompact(value, GetOutWriter(pr.cmd))
+			}
+			if err != nil {
+				log.Fatalf("Failed to convert output to JSON: %v (%+v)", err, value)
+			}
 		}
 		return
 	case "yaml":
@@ -224,6 +275,9 @@ func printCmdOutputCustom(pr printRequest, v any, table *Table) {
 	// format table if a transform is provided or there is no custom table
 	if pr.fields != "" || table == nil || len(table.Headers) == 0 {
 		var err error
+		if pr.fields == "" && pr.format == "html" {
+			v = canonicalizeData(v) // the columns are then derived from the keys of the items
+		}
 		table, err = createTable(v, pr.fields, table) // replaces the table
 		if err != nil {
 			log.Warnf("Failed to convert output data to a table: %v; reverting to YAML output", err)
@@ -234,11 +288,23 @@ func printCmdOutputCustom(pr printRequest, v any, table *Table) {
 		}
 	}
 
-	// display table
-	if table.Detail || pr.format == "detail" {
+	if pr.format == "html" {
+		if err := printHtml(pr.cmd, table); err != nil {
+			log.Fatalf("Failed to convert output to HTML: %v", err)
+		}
+		return
+	}
+
+	// display table, transposed if requested for wide rows
+	fitCells(table, pr.maxColWidth, pr.wrap)
+	if pr.format == "markdown" {
+		printMarkdown(pr.cmd, table, table.Detail || pr.transpose)
+		return
+	}
+	if table.Detail || pr.format == "detail" || pr.transpose {
 		printDetail(pr.cmd, table)
 	} else {
-		printTable(pr.cmd, table)
+		printTable(pr.cmd, table, pr.maxColWidth > 0)
 	}
 }
 
@@ -269,8 +335,9 @@ func printSimple(cmd *cobra.Command, v any) {
 	println(cmd, v)
 }
 
-// printTable prints a table, with header and one or more rows
-func printTable(cmd *cobra.Command, t *Table) {
+// printTable prints a table, with header and one or more rows. Cells are wrapped by the table writer unless
+// they were fitted already (see fitCells)
+func printTable(cmd *cobra.Command, t *Table, fitted bool) {
 	if t == nil {
 		printSimple(cmd, "Nothing to display")
 		return
@@ -280,6 +347,7 @@ func printTable(cmd *cobra.Command, t *Table) {
 	tw.SetCenterSeparator("")
 	tw.SetColumnSeparator("")
 	tw.SetRowSeparator("")
+	tw.SetAutoWrapText(!fitted)
 	if !t.OmitHeaders {
 		tw.SetHeader(t.Headers)
 	}
@@ -312,7 +380,9 @@ func printDetail(cmd *cobra.Command, t *Table) {
 			if t.OmitHeaders {
 				printf(cmd, "%v\n", entry[i])
 			} else {
-				printf(cmd, "%[1]*[2]s: %[3]v\n", labelWidth, t.Headers[i], entry[i])
+				// align the continuation lines of wrapped cells with the value
+				value := strings.ReplaceAll(entry[i], "\n", "\n"+strings.Repeat(" ", labelWidth+2))
+				printf(cmd, "%[1]*[2]s: %[3]v\n", labelWidth, t.Headers[i], value)
 			}
 		}
 		println(cmd)
@@ -442,6 +512,9 @@ func transformFields(v any, fieldsCommaList string) any {
 		qStr := fmt.Sprintf(". as $root|.items|{items: map({%s}),total:$root.total}", fieldsCommaList)
 		query, err := gojq.Parse(qStr)
 		if err != nil {
+			if colErr := ValidateFields(fieldsCommaList); colErr != nil {
+				log.Fatalf("Failed to parse field list %q: %v", fieldsCommaList, colErr)
+			}
 			log.Fatalf("Failed to parse field list %q as a jq expression %q: %v", fieldsCommaList, qStr, err)
 		}
 		iter := query.Run(v)
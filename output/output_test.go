@@ -17,8 +17,11 @@ package output
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 
 	"github.com/cisco-open/fsoc/test"
@@ -43,6 +46,7 @@ func TestPrintJSONAndYaml(t *testing.T) {
 		fixture string
 	}{
 		{format: "json", fixture: "./fixtures/output_json.txt"},
+		{format: "json-compact", fixture: "./fixtures/output_json_compact.txt"},
 		{format: "yaml", fixture: "./fixtures/output_yaml.txt"},
 	}
 
@@ -87,3 +91,174 @@ func TestPrintTable(t *testing.T) {
 	outActual := test.CaptureConsoleOutput(func() { printCmdOutputCustom(pr, nil, table) }, t)
 	require.Equal(t, outExpected, outActual)
 }
+
+func TestPrintTableTransposed(t *testing.T) {
+	pr := printRequest{format: "table", transpose: true}
+
+	table := &Table{
+		Headers: []string{"Field1", "Field2", "LongerField3"},
+	}
+	for i := 1; i <= 2; i++ {
+		rowString := []string{fmt.Sprintf("Row%d-Field1", i), fmt.Sprintf("%d", i), strconv.FormatBool(true)}
+		table.Lines = append(table.Lines, rowString)
+	}
+	outExpected, err := test.ReadFileToString("./fixtures/output_table_transposed.txt")
+	require.Nil(t, err)
+	outActual := test.CaptureConsoleOutput(func() { printCmdOutputCustom(pr, nil, table) }, t)
+	require.Equal(t, outExpected, outActual)
+
+	// machine formats are not affected
+	pr = printRequest{format: "json", transpose: true}
+	outExpected, err = test.ReadFileToString("./fixtures/output_json.txt")
+	require.Nil(t, err)
+	outActual = test.CaptureConsoleOutput(func() {
+		printCmdOutputCustom(pr, testStruct{Field1: "hello", Field2: 100, Field3: true}, nil)
+	}, t)
+	require.Equal(t, outExpected, outActual)
+}
+
+func TestFitCells(t *testing.T) {
+	table := &Table{
+		Headers: []string{"Name", "Reason"},
+		Lines:   [][]string{{"short", "the workload has no traffic"}, {"héllo wörld", "ünïcode"}},
+	}
+	fitCells(table, 8, false)
+	require.Equal(t, [][]string{{"short", "the wor…"}, {"héllo w…", "ünïcode"}}, table.Lines)
+
+	table.Lines = [][]string{{"short", "the workload has no traffic"}, {"ab", "abcdefghijkl"}}
+	fitCells(table, 8, true)
+	require.Equal(t, [][]string{{"short", "the\nworkload\nhas no\ntraffic"}, {"ab", "abcdefgh\nijkl"}}, table.Lines)
+
+	// color codes take no width and are kept, the reset one included
+	table.Lines = [][]string{{"\033[32m+12 ▲\033[0m", "\033[31mthe workload has no traffic\033[0m"}}
+	fitCells(table, 5, false)
+	require.Equal(t, [][]string{{"\033[32m+12 ▲\033[0m", "\033[31mthe …\033[0m"}}, table.Lines)
+	table.Lines = [][]string{{"\033[31mthe \033[1mworkload\033[0m has no traffic"}}
+	fitCells(table, 8, true)
+	require.Equal(t, [][]string{{"\033[31mthe\n\033[1mworkload\n\033[0mhas no\ntraffic"}}, table.Lines)
+
+	table.Lines = [][]string{{"unchanged without width"}}
+	fitCells(table, 0, false)
+	require.Equal(t, [][]string{{"unchanged without width"}}, table.Lines)
+}
+
+func TestValidateFields(t *testing.T) {
+	require.Nil(t, ValidateFields(`Type: .EventAttributes["appd.event.type"], TS: .Timestamp`))
+	require.Nil(t, ValidateFields(`Blockers: (.a // {}) | with_entries(select(.key | startswith("x,y"))), Name: .name`))
+	require.Nil(t, ValidateFields("*"))
+
+	err := ValidateFields(`Type: .EventAttributes["appd.event.type"], TS: .Timestamp[`)
+	require.ErrorContains(t, err, `field column "TS"`)
+
+	err = ValidateFields(`Type: .type, : .Timestamp`)
+	require.ErrorContains(t, err, "has no name")
+}
+
+func TestPrintJsonWithJq(t *testing.T) {
+	obj := struct {
+		Items []testStruct `json:"items"`
+		Total int          `json:"total"`
+	}{Items: []testStruct{{Field1: "hello", Field2: 1}, {Field1: "world", Field2: 2, Field3: true}}, Total: 2}
+
+	pr := printRequest{format: "json-compact", jq: `.items | map(select(.Field3)) | .[].Field1`}
+	outActual := test.CaptureConsoleOutput(func() { printCmdOutputCustom(pr, obj, nil) }, t)
+	require.Equal(t, "\"world\"\n", outActual)
+
+	// --fields does not project JSON output, --jq sees the full items
+	pr = printRequest{format: "json-compact", fields: "Name: .Field1", jq: `.items[0].Field2`}
+	outActual = test.CaptureConsoleOutput(func() { printCmdOutputCustom(pr, obj, nil) }, t)
+	require.Equal(t, "1\n", outActual)
+
+	// each value produced is printed in turn
+	pr = printRequest{format: "json-compact", jq: `.items[].Field2`}
+	outActual = test.CaptureConsoleOutput(func() { printCmdOutputCustom(pr, obj, nil) }, t)
+	require.Equal(t, "1\n2\n", outActual)
+
+	_, err := CompileJq(".items | map(")
+	require.ErrorContains(t, err, "invalid jq expression")
+	_, err = CompileJq("undefined_function")
+	require.ErrorContains(t, err, "invalid jq expression")
+}
+
+func TestPrintHtml(t *testing.T) {
+	htmlNow = func() time.Time { return time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC) }
+	defer func() { htmlNow = time.Now }()
+
+	pr := printRequest{format: "html"}
+	table := &Table{
+		Headers: []string{"Name", "Attributes"},
+		Lines:   [][]string{{"<b>first</b>", `{"cpu":0.5,"memory":"1Gi"}`}, {"second", "plain"}},
+	}
+	outActual := test.CaptureConsoleOutput(func() { printCmdOutputCustom(pr, nil, table) }, t)
+
+	require.Contains(t, outActual, "Generated at 2023-08-01T12:00:00Z")
+	require.Contains(t, outActual, "<tr><th>Name</th><th>Attributes</th></tr>")
+	require.Contains(t, outActual, "<td>&lt;b&gt;first&lt;/b&gt;</td>")
+	require.Contains(t, outActual, "<details><summary>2 attributes</summary><pre>{\n    &#34;cpu&#34;: 0.5,")
+	require.Contains(t, outActual, "<td>plain</td>")
+	require.Contains(t, outActual, "Total: 2")
+}
+
+func TestHtmlParametersRedactHeaders(t *testing.T) {
+	cmd := &cobra.Command{}
+	cmd.Flags().StringSlice("header", nil, "")
+	cmd.Flags().String("output", "", "")
+	require.NoError(t, cmd.ParseFlags([]string{"--header", "X-Api-Token=secret,Accept=application/json", "--header", "Authorization=Bearer abc", "--output", "html"}))
+
+	parameters := htmlParameters(cmd)
+	require.Equal(t, []htmlParameter{{Name: "--header", Value: "X-Api-Token=REDACTED, Accept=application/json, Authorization=REDACTED"}}, parameters)
+}
+
+func TestPrintMarkdown(t *testing.T) {
+	table := &Table{
+		Headers: []string{"Name", "Command"},
+		Lines:   [][]string{{"first", "grep a|b"}, {"second", "line1\nline2"}},
+	}
+
+	pr := printRequest{format: "markdown"}
+	outActual := test.CaptureConsoleOutput(func() { printCmdOutputCustom(pr, nil, table) }, t)
+	require.Equal(t, "| Name | Command |\n| --- | --- |\n| first | grep a\\|b |\n| second | line1<br>line2 |\n", outActual)
+
+	pr = printRequest{format: "markdown", transpose: true}
+	outActual = test.CaptureConsoleOutput(func() { printCmdOutputCustom(pr, nil, table) }, t)
+	require.Equal(t, "- **Name**: first\n- **Command**: grep a\\|b\n\n- **Name**: second\n- **Command**: line1<br>line2\n", outActual)
+}
+
+func TestJsonStream(t *testing.T) {
+	type trailer struct {
+		Total    int      `json:"total"`
+		Warnings []string `json:"warnings,omitempty"`
+	}
+	type payload struct {
+		Items    []testStruct `json:"items"`
+		Total    int          `json:"total"`
+		Warnings []string     `json:"warnings,omitempty"`
+	}
+	items := []testStruct{{Field1: "a<b", Field2: 1}, {Field1: "c", Field3: true}}
+
+	for _, compact := range []bool{false, true} {
+		for _, count := range []int{0, 1, 2} {
+			var streamed strings.Builder
+			stream := NewJsonStream(&streamed, "items", compact)
+			for _, item := range items[:count] {
+				require.Nil(t, stream.Add(item))
+			}
+			require.Equal(t, count, stream.Count())
+			require.Nil(t, stream.Close(trailer{Total: count, Warnings: []string{"partial"}}))
+
+			var expected strings.Builder
+			write := WriteJson
+			if compact {
+				write = WriteJsonCompact
+			}
+			require.Nil(t, write(payload{Items: append([]testStruct{}, items[:count]...), Total: count, Warnings: []string{"partial"}}, &expected))
+			require.Equal(t, expected.String(), streamed.String(), "compact %v, %v items", compact, count)
+		}
+	}
+
+	var streamed strings.Builder
+	stream := NewJsonStream(&streamed, "items", false)
+	require.Nil(t, stream.Close(nil))
+	require.Equal(t, "{\n    \"items\": []\n}\n", streamed.String())
+	require.ErrorContains(t, NewJsonStream(&streamed, "items", false).Close([]int{1}), "must be a JSON object")
+}
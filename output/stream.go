@@ -0,0 +1,150 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+// JsonStream writes a JSON object holding an array of items as the items are produced, rather than once they have
+// all been accumulated, so that memory stays bounded and output starts with the first item. The object is written
+// as WriteJson (or WriteJsonCompact) would write it, with the array first and the members of a trailer, such as
+// the total, after it
+type JsonStream struct {
+	w       io.Writer
+	name    string
+	compact bool
+	count   int
+	started bool
+}
+
+// NewJsonStream returns a stream writing to w the object whose array member is named name
+func NewJsonStream(w io.Writer, name string, compact bool) *JsonStream {
+	return &JsonStream{w: w, name: name, compact: compact}
+}
+
+// NewCmdJsonStream returns a stream of the command output in its json or json-compact output format. An error is
+// returned for the other formats and with --fields or --jq, which transform the output as a whole
+func NewCmdJsonStream(cmd *cobra.Command, name string) (*JsonStream, error) {
+	format, _ := cmd.Flags().GetString("output")
+	if format != "json" && format != "json-compact" {
+		return nil, fmt.Errorf("streaming requires the json or json-compact output format, not %q", format)
+	}
+	if fields, _ := cmd.Flags().GetString("fields"); fields != "" {
+		return nil, errors.New("streaming cannot be combined with --fields")
+	}
+	if jq, _ := cmd.Flags().GetString("jq"); jq != "" {
+		return nil, errors.New("streaming cannot be combined with --jq")
+	}
+	return NewJsonStream(GetOutWriter(cmd), name, format == "json-compact"), nil
+}
+
+// Count returns the number of items written so far
+func (s *JsonStream) Count() int {
+	return s.count
+}
+
+// Add writes an item of the array
+func (s *JsonStream) Add(item any) error {
+	var data []byte
+	var err error
+	if s.compact {
+		data, err = json.Marshal(item)
+	} else {
+		data, err = json.MarshalIndent(item, JsonIndent+JsonIndent, JsonIndent)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to convert item %v to JSON: %w", s.count+1, err)
+	}
+
+	var buf bytes.Buffer
+	if !s.started {
+		buf.WriteString(s.opening())
+	} else {
+		buf.WriteString(",")
+	}
+	if !s.compact {
+		buf.WriteString("\n" + JsonIndent + JsonIndent)
+	}
+	buf.Write(data)
+	if _, err := s.w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	s.started = true
+	s.count++
+	return nil
+}
+
+// Close ends the array and writes the members of trailer, a struct or map marshaled as a JSON object, after it
+func (s *JsonStream) Close(trailer any) error {
+	var members []byte
+	if trailer != nil {
+		var data []byte
+		var err error
+		if s.compact {
+			data, err = json.Marshal(trailer)
+		} else {
+			data, err = json.MarshalIndent(trailer, "", JsonIndent)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to convert the trailer to JSON: %w", err)
+		}
+		data = bytes.TrimSpace(data)
+		if len(data) < 2 || data[0] != '{' || data[len(data)-1] != '}' {
+			return fmt.Errorf("the trailer must be a JSON object, not %s", data)
+		}
+		members = bytes.TrimSpace(data[1 : len(data)-1])
+	}
+
+	var buf bytes.Buffer
+	switch {
+	case !s.started:
+		buf.WriteString(s.opening())
+		buf.WriteString("]")
+	case s.compact:
+		buf.WriteString("]")
+	default:
+		buf.WriteString("\n" + JsonIndent + "]")
+	}
+	if len(members) > 0 {
+		buf.WriteString(",")
+		if !s.compact {
+			buf.WriteString("\n" + JsonIndent)
+		}
+		buf.Write(members)
+	}
+	if s.compact {
+		buf.WriteString("}\n")
+	} else {
+		buf.WriteString("\n}\n")
+	}
+	_, err := s.w.Write(buf.Bytes())
+	return err
+}
+
+// opening returns the start of the object up to the opening of the array
+func (s *JsonStream) opening() string {
+	name, _ := json.Marshal(s.name)
+	if s.compact {
+		return fmt.Sprintf("{%s:[", name)
+	}
+	return fmt.Sprintf("{\n%v%s: [", JsonIndent, name)
+}
@@ -42,7 +42,9 @@ var FlagCurlifyRequests bool
 type Options struct {
 	Headers         map[string]string
 	ResponseHeaders map[string][]string // headers as returned by the call
+	ResponseStatus  int                 // HTTP status code as returned by the call, also set when the call fails
 	ExpectedErrors  []int               // log expected error status codes as Info rather than Error
+	Trace           bool                // dump the request and the raw response to stderr, with credentials redacted
 }
 
 // JSONGet performs a GET request and parses the response as JSON
@@ -227,6 +229,9 @@ func httpRequest(method string, path string, body any, out any, options *Options
 	}
 
 	// execute request, speculatively, assuming the auth token is valid
+	if options.Trace {
+		traceRequest(req)
+	}
 	callCtx.startSpinner(fmt.Sprintf("Platform API call (%v %v)", req.Method, urlDisplayPath(req.URL)))
 	resp, err := client.Do(req)
 	if err != nil {
@@ -241,6 +246,10 @@ func httpRequest(method string, path string, body any, out any, options *Options
 	if err != nil {
 		return fmt.Errorf("failed reading response to %v to %q (status %v): %w", method, req.URL.String(), resp.StatusCode, err)
 	}
+	if options.Trace {
+		callCtx.stopSpinnerHide()
+		traceResponse(resp, respBytes)
+	}
 
 	// handle special case when access token needs to be refreshed and request retried
 	if resp.StatusCode == http.StatusForbidden {
@@ -258,6 +267,9 @@ func httpRequest(method string, path string, body any, out any, options *Options
 		if err != nil {
 			return err // error should have enough context
 		}
+		if options.Trace {
+			traceRequest(req)
+		}
 		callCtx.startSpinner(fmt.Sprintf("Platform API call, retry after login (%v %v)", req.Method, urlDisplayPath(req.URL)))
 		resp, err = client.Do(req)
 		// leave the spinner until the outcome is finalized, return will stop/fail it
@@ -271,6 +283,18 @@ func httpRequest(method string, path string, body any, out any, options *Options
 		if err != nil {
 			return fmt.Errorf("failed reading response to %v to %q (status %v): %w", method, req.URL.String(), resp.StatusCode, err)
 		}
+		if options.Trace {
+			callCtx.stopSpinnerHide()
+			traceResponse(resp, respBytes)
+		}
+	}
+
+	// return response status and headers, whether success or error
+	options.ResponseStatus = resp.StatusCode
+	if resp.Header != nil {
+		options.ResponseHeaders = map[string][]string(resp.Header)
+	} else {
+		options.ResponseHeaders = nil
 	}
 
 	// return if API call response indicates error
@@ -312,15 +336,6 @@ func httpRequest(method string, path string, body any, out any, options *Options
 		}
 	}
 
-	// return response headers
-	if options != nil {
-		if resp.Header != nil {
-			options.ResponseHeaders = map[string][]string(resp.Header)
-		} else {
-			options.ResponseHeaders = nil
-		}
-	}
-
 	return nil
 }
 
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"bytes"
 	"net/http"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -28,3 +30,22 @@ func TestPrepareJSONRequest(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "http://localhost:8080/test/path/1", req.URL.String())
 }
+
+func TestTraceRequestRedactsCredentials(t *testing.T) {
+	var buf bytes.Buffer
+	traceWriter = &buf
+	defer func() { traceWriter = os.Stderr }()
+
+	cfg := &config.Context{
+		URL:   "http://localhost:8080",
+		Token: "secret-token-value",
+	}
+	req, err := prepareHTTPRequest(cfg, &http.Client{}, "POST", "/monitoring/v1/query/execute", map[string]string{"query": "FETCH id"}, nil)
+	assert.Nil(t, err)
+	traceRequest(req)
+
+	assert.NotContains(t, buf.String(), "secret-token-value")
+	assert.Contains(t, buf.String(), "> Authorization: REDACTED")
+	assert.Contains(t, buf.String(), "> POST http://localhost:8080/monitoring/v1/query/execute")
+	assert.Contains(t, buf.String(), "\"query\": \"FETCH id\"")
+}
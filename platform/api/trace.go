@@ -0,0 +1,92 @@
+// Copyright 2023 Cisco Systems, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// traceWriter receives the dumps of traced calls
+var traceWriter io.Writer = os.Stderr
+
+// redactedHeaders are the headers whose values are never included in traces, in canonical form
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// traceRequest dumps the request line, headers and body of a traced call
+func traceRequest(req *http.Request) {
+	var body []byte
+	if req.GetBody != nil {
+		if reader, err := req.GetBody(); err == nil {
+			body, _ = io.ReadAll(reader)
+		}
+	}
+	fmt.Fprintf(traceWriter, "> %v %v\n", req.Method, req.URL.String())
+	traceHeaders(">", req.Header)
+	traceBody(body)
+}
+
+// traceResponse dumps the status, headers and body of a traced call's response, as received before parsing
+func traceResponse(resp *http.Response, body []byte) {
+	fmt.Fprintf(traceWriter, "< %v\n", resp.Status)
+	traceHeaders("<", resp.Header)
+	traceBody(body)
+}
+
+func traceHeaders(prefix string, headers http.Header) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := strings.Join(headers[name], ", ")
+		if IsRedactedHeader(name) {
+			value = "REDACTED"
+		}
+		fmt.Fprintf(traceWriter, "%v %v: %v\n", prefix, name, value)
+	}
+}
+
+// IsRedactedHeader reports whether the header may carry credentials, so that its value is never shown
+func IsRedactedHeader(name string) bool {
+	canonical := http.CanonicalHeaderKey(name)
+	for _, redacted := range redactedHeaders {
+		if canonical == redacted {
+			return true
+		}
+	}
+	lower := strings.ToLower(name)
+	return strings.Contains(lower, "token") || strings.Contains(lower, "secret")
+}
+
+// traceBody dumps the body, pretty-printed if it is JSON
+func traceBody(body []byte) {
+	if len(body) < 1 {
+		fmt.Fprintln(traceWriter)
+		return
+	}
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, body, "", "  "); err == nil {
+		body = pretty.Bytes()
+	}
+	fmt.Fprintf(traceWriter, "\n%s\n\n", body)
+}